@@ -0,0 +1,126 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/rancher/norman/api/builtin"
+	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/parse"
+	"github.com/rancher/norman/store/operation"
+	"github.com/rancher/norman/types"
+	"github.com/sirupsen/logrus"
+)
+
+func hasAsyncAction(schema *types.Schema) bool {
+	for _, action := range schema.ResourceActions {
+		if action.Async {
+			return true
+		}
+	}
+	for _, action := range schema.CollectionActions {
+		if action.Async {
+			return true
+		}
+	}
+	return false
+}
+
+// addOperationSchema adds the schema of the operations of asynchronous actions to version, once. The
+// version can't have a schema of its own with the ID of operations.
+func (s *Server) addOperationSchema(version types.APIVersion) error {
+	if s.operationPaths[version.Path] {
+		return nil
+	}
+	if s.Schemas.Schema(&version, operation.SchemaID) != nil {
+		return operationCollision(version)
+	}
+
+	schema := builtin.Operation
+	schema.Version = version
+	schema.Store = s.Operations
+	s.Schemas.AddSchema(schema)
+
+	if s.operationPaths == nil {
+		s.operationPaths = map[string]bool{}
+	}
+	s.operationPaths[version.Path] = true
+	return nil
+}
+
+func operationCollision(version types.APIVersion) error {
+	return fmt.Errorf("schema %s of version %s collides with the operations of asynchronous actions",
+		operation.SchemaID, version.Path)
+}
+
+// handleAsyncAction runs the handler of an action in the background and answers with the operation
+// tracking it. The handler runs with a copy of the request whose context and body outlive the request,
+// and what it writes in response becomes the result or error of the operation.
+func (s *Server) handleAsyncAction(action *types.Action, apiRequest *types.APIContext) error {
+	// the server closes the body once the request is answered, before the handler reads it
+	req := apiRequest.Request.Clone(context.WithoutCancel(apiRequest.Request.Context()))
+	if err := parse.BufferBody(req); err != nil {
+		return err
+	}
+
+	op := s.Operations.Start(apiRequest, apiRequest.Action)
+
+	result := &capturingWriter{}
+	async := *apiRequest
+	async.ResponseWriter = result
+	async.Response = discardResponse{header: http.Header{}}
+	async.Request = req.WithContext(operation.NewContext(req.Context(), op))
+
+	go func() {
+		defer func() {
+			if err := recover(); err != nil {
+				logrus.Errorf("Panic running action %s of %s: %v\n%s", async.Action, async.Type, err, debug.Stack())
+				op.Fail(serverError(fmt.Sprint(err)))
+			}
+		}()
+
+		if err := async.Schema.ActionHandler(async.Action, action, &async); err != nil {
+			s.handleError(&async, err)
+		}
+
+		data, err := renderResult(&async, result.obj)
+		switch {
+		case err != nil:
+			op.Fail(serverError(fmt.Sprintf("Failed to render the output of the action: %v", err)))
+		case result.code >= http.StatusBadRequest:
+			op.Fail(data)
+		default:
+			op.Succeed(data, action.Output)
+		}
+	}()
+
+	apiRequest.WriteResponse(http.StatusAccepted, op.Data())
+	return nil
+}
+
+func serverError(message string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":    "/meta/schemas/error",
+		"status":  httperror.ServerError.Status,
+		"code":    httperror.ServerError.Code,
+		"message": message,
+	}
+}
+
+// discardResponse is the response of an asynchronous action, which is answered before the handler runs
+type discardResponse struct {
+	header http.Header
+}
+
+func (d discardResponse) Header() http.Header {
+	return d.header
+}
+
+func (d discardResponse) Write(data []byte) (int, error) {
+	return len(data), nil
+}
+
+func (d discardResponse) WriteHeader(int) {
+}
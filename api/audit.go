@@ -0,0 +1,184 @@
+package api
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rancher/norman/parse"
+	"github.com/rancher/norman/pkg/audit"
+	"github.com/rancher/norman/types"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+const (
+	userHeader  = "Impersonate-User"
+	groupHeader = "Impersonate-Group"
+)
+
+var auditMethods = map[string]bool{
+	http.MethodPost:   true,
+	http.MethodPut:    true,
+	http.MethodPatch:  true,
+	http.MethodDelete: true,
+}
+
+// auditWriter captures the resource written in response to a mutating request, and records the audit
+// event of the request once its status is known
+type auditWriter struct {
+	types.ResponseWriter
+	apiContext *types.APIContext
+	sink       audit.Sink
+	event      audit.Event
+	before     map[string]interface{}
+	after      map[string]interface{}
+}
+
+func (a *auditWriter) Write(apiContext *types.APIContext, code int, obj interface{}) {
+	if data, ok := obj.(map[string]interface{}); ok && code < http.StatusBadRequest {
+		a.after = data
+	}
+	a.ResponseWriter.Write(apiContext, code, obj)
+}
+
+// startAudit prepares the audit event of a mutating request, reading its body and the resource it changes
+// before any handler runs. The returned writer, nil if the request isn't audited, records the event once
+// finished.
+func (s *Server) startAudit(apiRequest *types.APIContext) *auditWriter {
+	if s.AuditSink == nil || apiRequest.Schema == nil || !auditMethods[apiRequest.Method] {
+		return nil
+	}
+
+	a := &auditWriter{
+		ResponseWriter: apiRequest.ResponseWriter,
+		apiContext:     apiRequest,
+		sink:           s.AuditSink,
+		event: audit.Event{
+			Time:        time.Now(),
+			User:        apiRequest.Request.Header.Get(userHeader),
+			Groups:      apiRequest.Request.Header.Values(groupHeader),
+			Method:      apiRequest.Method,
+			Schema:      apiRequest.Schema.ID,
+			ID:          apiRequest.ID,
+			Action:      apiRequest.Action,
+			DryRun:      apiRequest.Option("dryRun") == "true",
+			RequestBody: readAuditBody(apiRequest),
+		},
+	}
+	if apiRequest.ID != "" && apiRequest.Schema.Store != nil {
+		a.before, _ = apiRequest.Schema.Store.ByID(apiRequest, apiRequest.Schema, apiRequest.ID)
+	}
+	apiRequest.ResponseWriter = a
+	return a
+}
+
+// finish records the audit event of the request with the status it was answered with
+func (a *auditWriter) finish(status int) {
+	if a == nil {
+		return
+	}
+
+	apiRequest := a.apiContext
+	after := a.after
+	switch {
+	case status >= http.StatusBadRequest:
+		after = a.before
+	case apiRequest.Method == http.MethodDelete:
+		after = nil
+	case apiRequest.Action != "" && apiRequest.ID != "":
+		// the response of an action isn't the resource, so read what the action left
+		after, _ = apiRequest.Schema.Store.ByID(apiRequest, apiRequest.Schema, apiRequest.ID)
+	case apiRequest.Action != "":
+		after = nil
+	}
+
+	a.event.Status = status
+	a.event.Diff = audit.Diff(
+		audit.Redact(apiRequest.Schemas, apiRequest.Schema, a.before),
+		audit.Redact(apiRequest.Schemas, apiRequest.Schema, after))
+	if err := a.sink.Record(a.event); err != nil {
+		logrus.Errorf("Failed to record audit event of %s %s: %v", a.event.Method, apiRequest.Request.URL.Path, err)
+	}
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// readAuditBody returns the redacted body of a request, leaving the body in place for the handlers
+func readAuditBody(apiRequest *types.APIContext) interface{} {
+	req := apiRequest.Request
+	if req.Body == nil {
+		return nil
+	}
+
+	// read no more than the handlers do, bodies over the limit are left out of the event
+	content, err := io.ReadAll(io.LimitReader(req.Body, parse.MaxBodySize+1))
+	req.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(content), req.Body), Closer: req.Body}
+	if err != nil || len(content) > parse.MaxBodySize || len(bytes.TrimSpace(content)) == 0 {
+		return nil
+	}
+
+	var body interface{}
+	if err := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(content), len(content)).Decode(&body); err != nil {
+		return string(content)
+	}
+
+	switch data := body.(type) {
+	case map[string]interface{}:
+		if apiRequest.Action != "" {
+			// the input of an action isn't the resource, so only redact fields that are sensitive on both
+			return redactActionInput(apiRequest, data)
+		}
+		return audit.Redact(apiRequest.Schemas, apiRequest.Schema, data)
+	case []interface{}:
+		return redactPatch(apiRequest.Schema, data)
+	}
+	return body
+}
+
+func redactActionInput(apiRequest *types.APIContext, data map[string]interface{}) map[string]interface{} {
+	action, ok := apiRequest.Schema.ResourceActions[apiRequest.Action]
+	if apiRequest.ID == "" {
+		action, ok = apiRequest.Schema.CollectionActions[apiRequest.Action]
+	}
+	if !ok || action.Input == "" {
+		return data
+	}
+	input := apiRequest.Schemas.Schema(&apiRequest.Schema.Version, action.Input)
+	if input == nil {
+		return data
+	}
+	return audit.Redact(apiRequest.Schemas, input, data)
+}
+
+// redactPatch redacts the values a JSON patch sets on sensitive fields
+func redactPatch(schema *types.Schema, operations []interface{}) []interface{} {
+	result := make([]interface{}, len(operations))
+	for i, operation := range operations {
+		op, ok := operation.(map[string]interface{})
+		if !ok {
+			result[i] = operation
+			continue
+		}
+
+		path, _ := op["path"].(string)
+		name := strings.SplitN(strings.TrimPrefix(path, "/"), "/", 2)[0]
+		if field, ok := schema.ResourceFields[name]; ok && audit.Sensitive(field) {
+			redacted := make(map[string]interface{}, len(op))
+			for k, v := range op {
+				redacted[k] = v
+			}
+			if _, ok := redacted["value"]; ok {
+				redacted["value"] = audit.Redacted
+			}
+			op = redacted
+		}
+		result[i] = op
+	}
+	return result
+}
@@ -0,0 +1,688 @@
+package builtin
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/types"
+	"github.com/rancher/norman/types/convert"
+	"github.com/rancher/norman/types/definition"
+	"github.com/rancher/norman/types/slice"
+)
+
+const (
+	OpenAPIVersion = "3.0.3"
+
+	componentsPrefix = "#/components/schemas/"
+	errorComponent   = "error"
+)
+
+// OpenAPIDocument is the subset of the OpenAPI 3 document model norman renders
+type OpenAPIDocument struct {
+	OpenAPI    string                     `json:"openapi"`
+	Info       OpenAPIInfo                `json:"info"`
+	Servers    []OpenAPIServer            `json:"servers,omitempty"`
+	Paths      map[string]OpenAPIPathItem `json:"paths"`
+	Components OpenAPIComponents          `json:"components"`
+}
+
+type OpenAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type OpenAPIServer struct {
+	URL string `json:"url"`
+}
+
+type OpenAPIPathItem map[string]*OpenAPIOperation
+
+type OpenAPIOperation struct {
+	OperationID string                      `json:"operationId,omitempty"`
+	Tags        []string                    `json:"tags,omitempty"`
+	Parameters  []OpenAPIParameter          `json:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*OpenAPIResponse `json:"responses"`
+	// Actions describes each action a POST operation runs by the value of its action parameter
+	Actions map[string]*OpenAPIOperation `json:"x-norman-actions,omitempty"`
+}
+
+type OpenAPIParameter struct {
+	Name        string         `json:"name"`
+	In          string         `json:"in"`
+	Description string         `json:"description,omitempty"`
+	Required    bool           `json:"required,omitempty"`
+	Explode     *bool          `json:"explode,omitempty"`
+	Schema      *OpenAPISchema `json:"schema"`
+}
+
+type OpenAPIRequestBody struct {
+	Required bool                        `json:"required,omitempty"`
+	Content  map[string]OpenAPIMediaType `json:"content"`
+}
+
+type OpenAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]OpenAPIMediaType `json:"content,omitempty"`
+}
+
+type OpenAPIMediaType struct {
+	Schema *OpenAPISchema `json:"schema"`
+}
+
+type OpenAPIComponents struct {
+	Schemas map[string]*OpenAPISchema `json:"schemas"`
+}
+
+type OpenAPISchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Description          string                    `json:"description,omitempty"`
+	Nullable             bool                      `json:"nullable,omitempty"`
+	ReadOnly             bool                      `json:"readOnly,omitempty"`
+	WriteOnly            bool                      `json:"writeOnly,omitempty"`
+	Default              interface{}               `json:"default,omitempty"`
+	Enum                 []string                  `json:"enum,omitempty"`
+	MinLength            *int64                    `json:"minLength,omitempty"`
+	MaxLength            *int64                    `json:"maxLength,omitempty"`
+	Minimum              *int64                    `json:"minimum,omitempty"`
+	Maximum              *int64                    `json:"maximum,omitempty"`
+	Items                *OpenAPISchema            `json:"items,omitempty"`
+	Properties           map[string]*OpenAPISchema `json:"properties,omitempty"`
+	AdditionalProperties *OpenAPISchema            `json:"additionalProperties,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+	OneOf                []*OpenAPISchema          `json:"oneOf,omitempty"`
+	AllOf                []*OpenAPISchema          `json:"allOf,omitempty"`
+}
+
+// OpenAPIHandler renders all schemas of the requested API version as an OpenAPI 3 document
+func OpenAPIHandler(apiContext *types.APIContext, next types.RequestHandler) error {
+	if apiContext.ID != "" {
+		return httperror.NewAPIError(httperror.NotFound, "")
+	}
+
+	doc := NewOpenAPIDocument(apiContext, *apiContext.Version)
+
+	encoder := types.JSONEncoder
+	contentType := "application/json"
+	if apiContext.ResponseFormat == "yaml" {
+		encoder = types.YAMLEncoder
+		contentType = "application/yaml"
+	}
+
+	apiContext.Response.Header().Set("content-type", contentType)
+	apiContext.Response.WriteHeader(http.StatusOK)
+	return encoder(apiContext.Response, doc)
+}
+
+// NewOpenAPIDocument builds the OpenAPI document of all schemas of version the request has access to.
+// OpenAPI paths can't hold the query string norman addresses actions with, so the actions of a path are
+// rendered as its POST operation with an action query parameter, and described one by one in the
+// x-norman-actions extension of the operation.
+func NewOpenAPIDocument(apiContext *types.APIContext, version types.APIVersion) *OpenAPIDocument {
+	doc := &OpenAPIDocument{
+		OpenAPI: OpenAPIVersion,
+		Info: OpenAPIInfo{
+			Title:   version.Group,
+			Version: version.Version,
+		},
+		Servers: []OpenAPIServer{
+			{URL: apiContext.URLBuilder.Version(version)},
+		},
+		Paths: map[string]OpenAPIPathItem{},
+		Components: OpenAPIComponents{
+			Schemas: map[string]*OpenAPISchema{
+				errorComponent: errorSchema(),
+			},
+		},
+	}
+
+	b := &openAPIBuilder{
+		apiContext: apiContext,
+		doc:        doc,
+		schemas:    map[string]*types.Schema{},
+	}
+
+	for _, schema := range apiContext.Schemas.SchemasForVersion(version) {
+		if schema.Enabled != nil && !schema.Enabled() {
+			continue
+		}
+		b.schemas[schema.ID] = schema
+	}
+
+	for _, schema := range b.schemas {
+		if !b.visible(schema) {
+			continue
+		}
+
+		b.addComponent(schema)
+		b.addCollectionPaths(schema)
+		b.addResourcePaths(schema)
+	}
+
+	return doc
+}
+
+type openAPIBuilder struct {
+	apiContext *types.APIContext
+	doc        *OpenAPIDocument
+	schemas    map[string]*types.Schema
+}
+
+// visible returns true if the request can list or get the resources of schema
+func (b *openAPIBuilder) visible(schema *types.Schema) bool {
+	return schema.CanList(b.apiContext) == nil || schema.CanGet(b.apiContext) == nil
+}
+
+// addComponent adds the component schema of schema, types are only added once visible resources or the
+// types they use refer to them
+func (b *openAPIBuilder) addComponent(schema *types.Schema) {
+	if _, ok := b.doc.Components.Schemas[schema.ID]; ok {
+		return
+	}
+
+	// added before it is built, as it may refer to itself
+	component := &OpenAPISchema{}
+	b.doc.Components.Schemas[schema.ID] = component
+	*component = *b.typeSchema(schema)
+}
+
+// collectionComponent returns the name of the component of the collection of schema, its ID followed by
+// Collection, with underscores appended while that is the ID of a schema that may be a component too
+func (b *openAPIBuilder) collectionComponent(schema *types.Schema) string {
+	name := schema.ID + "Collection"
+	for {
+		_, ok := b.schemas[name]
+		if !ok && (b.apiContext == nil || b.apiContext.Schemas.Schema(&Version, name) == nil) {
+			return name
+		}
+		name += "_"
+	}
+}
+
+func (b *openAPIBuilder) addCollectionPaths(schema *types.Schema) {
+	path := "/" + strings.ToLower(schema.PluralName)
+	item := OpenAPIPathItem{}
+
+	if slice.ContainsString(schema.CollectionMethods, http.MethodGet) && schema.CanList(b.apiContext) == nil {
+		collection := b.collectionComponent(schema)
+		b.doc.Components.Schemas[collection] = collectionSchema(schema)
+		item["get"] = &OpenAPIOperation{
+			OperationID: "list" + schema.CodeNamePlural,
+			Tags:        []string{schema.ID},
+			Parameters:  b.listParameters(schema),
+			Responses:   responses(http.StatusOK, ref(collection)),
+		}
+	}
+
+	if slice.ContainsString(schema.CollectionMethods, http.MethodPost) && schema.CanCreate(b.apiContext) == nil {
+		item["post"] = &OpenAPIOperation{
+			OperationID: "create" + schema.CodeName,
+			Tags:        []string{schema.ID},
+			RequestBody: b.requestBody(schema.ID),
+			Responses:   responses(http.StatusCreated, ref(schema.ID)),
+		}
+	}
+
+	b.addActions(item, schema, "collectionAction", schema.CollectionActions, nil)
+
+	if len(item) > 0 {
+		b.doc.Paths[path] = item
+	}
+}
+
+func (b *openAPIBuilder) addResourcePaths(schema *types.Schema) {
+	path := "/" + strings.ToLower(schema.PluralName) + "/{id}"
+	params := []OpenAPIParameter{
+		{
+			Name:     "id",
+			In:       "path",
+			Required: true,
+			Schema:   &OpenAPISchema{Type: "string"},
+		},
+	}
+	item := OpenAPIPathItem{}
+
+	if slice.ContainsString(schema.ResourceMethods, http.MethodGet) && schema.CanGet(b.apiContext) == nil {
+		item["get"] = &OpenAPIOperation{
+			OperationID: "get" + schema.CodeName,
+			Tags:        []string{schema.ID},
+			Parameters:  params,
+			Responses:   responses(http.StatusOK, ref(schema.ID)),
+		}
+	}
+
+	if slice.ContainsString(schema.ResourceMethods, http.MethodPut) && schema.CanUpdate(b.apiContext) == nil {
+		item["put"] = &OpenAPIOperation{
+			OperationID: "update" + schema.CodeName,
+			Tags:        []string{schema.ID},
+			Parameters:  params,
+			RequestBody: b.requestBody(schema.ID),
+			Responses:   responses(http.StatusOK, ref(schema.ID)),
+		}
+	}
+
+	if slice.ContainsString(schema.ResourceMethods, http.MethodPatch) && schema.CanUpdate(b.apiContext) == nil {
+		item["patch"] = &OpenAPIOperation{
+			OperationID: "patch" + schema.CodeName,
+			Tags:        []string{schema.ID},
+			Parameters:  params,
+			RequestBody: b.patchRequestBody(schema.ID),
+			Responses:   responses(http.StatusOK, ref(schema.ID)),
+		}
+	}
+
+	if slice.ContainsString(schema.ResourceMethods, http.MethodDelete) && schema.CanDelete(b.apiContext) == nil {
+		item["delete"] = &OpenAPIOperation{
+			OperationID: "delete" + schema.CodeName,
+			Tags:        []string{schema.ID},
+			Parameters:  params,
+			Responses:   responses(http.StatusOK, ref(schema.ID)),
+		}
+	}
+
+	b.addActions(item, schema, "action", schema.ResourceActions, params)
+
+	if len(item) > 0 {
+		b.doc.Paths[path] = item
+	}
+}
+
+// addActions renders actions as the POST operation of item, selected by the action query parameter. The
+// request bodies and responses of the operation are the union of those of the actions, and of creating a
+// resource if the POST operation of item creates one without the parameter.
+func (b *openAPIBuilder) addActions(item OpenAPIPathItem, schema *types.Schema, prefix string, actions map[string]types.Action, params []OpenAPIParameter) {
+	names := sortedActions(actions)
+	if len(names) == 0 {
+		return
+	}
+
+	op := &OpenAPIOperation{
+		OperationID: prefix + schema.CodeName,
+		Tags:        []string{schema.ID},
+		Actions:     map[string]*OpenAPIOperation{},
+	}
+	variants := make([]*OpenAPIOperation, 0, len(names)+1)
+	for _, name := range names {
+		action := b.actionOperation(schema, prefix, name, actions[name], params)
+		op.Actions[name] = action
+		variants = append(variants, action)
+	}
+
+	actionParam := OpenAPIParameter{
+		Name:        "action",
+		In:          "query",
+		Description: "The action to run",
+		Required:    true,
+		Schema:      &OpenAPISchema{Type: "string", Enum: names},
+	}
+	if create := item["post"]; create != nil {
+		op.OperationID = create.OperationID
+		actionParam.Description = "The action to run, a " + schema.ID + " is created without it"
+		actionParam.Required = false
+		variants = append([]*OpenAPIOperation{create}, variants...)
+	}
+	op.Parameters = append(append([]OpenAPIParameter{}, params...), actionParam)
+	op.RequestBody = mergeRequestBodies(variants)
+	op.Responses = mergeResponses(variants)
+
+	item["post"] = op
+}
+
+func (b *openAPIBuilder) actionOperation(schema *types.Schema, prefix, name string, action types.Action, params []OpenAPIParameter) *OpenAPIOperation {
+	op := &OpenAPIOperation{
+		OperationID: prefix + schema.CodeName + convert.Capitalize(name),
+		Tags:        []string{schema.ID},
+		Parameters:  params,
+		Responses:   responses(http.StatusOK, nil),
+	}
+	if action.Input != "" {
+		op.RequestBody = b.requestBody(action.Input)
+	}
+	if action.Output != "" {
+		op.Responses = responses(http.StatusOK, b.fieldTypeSchema(action.Output))
+	}
+	// asynchronous actions answer with the operation whose result is the output
+	if action.Async {
+		op.Responses = responses(http.StatusAccepted, b.fieldTypeSchema(Operation.ID))
+	}
+	return op
+}
+
+func (b *openAPIBuilder) listParameters(schema *types.Schema) []OpenAPIParameter {
+	params := []OpenAPIParameter{
+		{Name: "limit", In: "query", Schema: &OpenAPISchema{Type: "integer"}},
+		{Name: "marker", In: "query", Schema: &OpenAPISchema{Type: "string"}},
+		{Name: "sort", In: "query", Schema: &OpenAPISchema{Type: "string"}},
+		{Name: "order", In: "query", Schema: &OpenAPISchema{Type: "string", Enum: []string{string(types.ASC), string(types.DESC)}}},
+		{Name: "fields", In: "query", Schema: &OpenAPISchema{Type: "string"}},
+		{Name: "include", In: "query", Schema: &OpenAPISchema{Type: "string"}},
+		{Name: "summary", In: "query", Schema: &OpenAPISchema{Type: "string"}},
+		{Name: "filter", In: "query", Schema: &OpenAPISchema{Type: "string"}},
+	}
+
+	if len(schema.SearchFields) > 0 {
+		params = append(params, OpenAPIParameter{Name: "q", In: "query", Schema: &OpenAPISchema{Type: "string"}})
+	}
+
+	var names []string
+	for name := range schema.CollectionFilters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, mod := range schema.CollectionFilters[name].Modifiers {
+			params = append(params, b.filterParameter(schema, name, mod))
+		}
+	}
+
+	return params
+}
+
+func (b *openAPIBuilder) filterParameter(schema *types.Schema, name string, mod types.ModifierType) OpenAPIParameter {
+	param := OpenAPIParameter{
+		Name:   name + "_" + string(mod),
+		In:     "query",
+		Schema: b.fieldSchema(schema.ResourceFields[name]),
+	}
+	param.Schema.Default = nil
+	param.Schema.ReadOnly = false
+	param.Schema.WriteOnly = false
+
+	switch mod {
+	case types.ModifierEQ:
+		param.Name = name
+	case types.ModifierNull, types.ModifierNotNull:
+		param.Schema = &OpenAPISchema{Type: "string"}
+	case types.ModifierIn, types.ModifierNotIn:
+		param.Explode = &[]bool{true}[0]
+		param.Schema = &OpenAPISchema{
+			Type:  "array",
+			Items: param.Schema,
+		}
+	}
+
+	return param
+}
+
+func (b *openAPIBuilder) requestBody(typeName string) *OpenAPIRequestBody {
+	return &OpenAPIRequestBody{
+		Required: true,
+		Content: map[string]OpenAPIMediaType{
+			"application/json": {Schema: b.fieldTypeSchema(typeName)},
+		},
+	}
+}
+
+func (b *openAPIBuilder) patchRequestBody(typeName string) *OpenAPIRequestBody {
+	operation := &OpenAPISchema{
+		Type:     "object",
+		Required: []string{"op", "path"},
+		Properties: map[string]*OpenAPISchema{
+			"op": {
+				Type: "string",
+				Enum: []string{"add", "remove", "replace", "move", "copy", "test"},
+			},
+			"path":  {Type: "string"},
+			"from":  {Type: "string"},
+			"value": {},
+		},
+	}
+
+	return &OpenAPIRequestBody{
+		Required: true,
+		Content: map[string]OpenAPIMediaType{
+			types.JSONPatchType:  {Schema: &OpenAPISchema{Type: "array", Items: operation}},
+			types.MergePatchType: {Schema: b.fieldTypeSchema(typeName)},
+		},
+	}
+}
+
+// mergeRequestBodies returns the request body accepting the JSON body of any of ops, only required if
+// all of them require one
+func mergeRequestBodies(ops []*OpenAPIOperation) *OpenAPIRequestBody {
+	var schemas []*OpenAPISchema
+	required := true
+	for _, op := range ops {
+		if op.RequestBody == nil {
+			required = false
+			continue
+		}
+		schemas = appendSchema(schemas, op.RequestBody.Content["application/json"].Schema)
+	}
+	if len(schemas) == 0 {
+		return nil
+	}
+
+	return &OpenAPIRequestBody{
+		Required: required,
+		Content: map[string]OpenAPIMediaType{
+			"application/json": {Schema: oneOf(schemas)},
+		},
+	}
+}
+
+// mergeResponses returns the responses of any of ops, the content of a status is one of the contents ops
+// answer with that status
+func mergeResponses(ops []*OpenAPIOperation) map[string]*OpenAPIResponse {
+	result := map[string]*OpenAPIResponse{}
+	contents := map[string][]*OpenAPISchema{}
+	for _, op := range ops {
+		for status, response := range op.Responses {
+			if _, ok := result[status]; !ok {
+				result[status] = &OpenAPIResponse{Description: response.Description}
+			}
+			if media, ok := response.Content["application/json"]; ok {
+				contents[status] = appendSchema(contents[status], media.Schema)
+			}
+		}
+	}
+
+	for status, schemas := range contents {
+		result[status].Content = map[string]OpenAPIMediaType{
+			"application/json": {Schema: oneOf(schemas)},
+		}
+	}
+	return result
+}
+
+// appendSchema appends schema to schemas unless it refers to a component already in schemas
+func appendSchema(schemas []*OpenAPISchema, schema *OpenAPISchema) []*OpenAPISchema {
+	for _, existing := range schemas {
+		if schema.Ref != "" && existing.Ref == schema.Ref {
+			return schemas
+		}
+	}
+	return append(schemas, schema)
+}
+
+func oneOf(schemas []*OpenAPISchema) *OpenAPISchema {
+	if len(schemas) == 1 {
+		return schemas[0]
+	}
+	return &OpenAPISchema{OneOf: schemas}
+}
+
+func responses(status int, schema *OpenAPISchema) map[string]*OpenAPIResponse {
+	ok := &OpenAPIResponse{
+		Description: http.StatusText(status),
+	}
+	if schema != nil {
+		ok.Content = map[string]OpenAPIMediaType{
+			"application/json": {Schema: schema},
+		}
+	}
+
+	return map[string]*OpenAPIResponse{
+		strconv.Itoa(status): ok,
+		"default": {
+			Description: "Error",
+			Content: map[string]OpenAPIMediaType{
+				"application/json": {Schema: ref(errorComponent)},
+			},
+		},
+	}
+}
+
+func sortedActions(actions map[string]types.Action) []string {
+	var names []string
+	for name := range actions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func ref(typeName string) *OpenAPISchema {
+	return &OpenAPISchema{Ref: componentsPrefix + typeName}
+}
+
+func errorSchema() *OpenAPISchema {
+	b := &openAPIBuilder{}
+	result := b.typeSchema(&Error)
+	result.Properties["type"] = &OpenAPISchema{Type: "string"}
+	result.Required = []string{"code", "status", "type"}
+	return result
+}
+
+func collectionSchema(schema *types.Schema) *OpenAPISchema {
+	stringMap := &OpenAPISchema{
+		Type:                 "object",
+		AdditionalProperties: &OpenAPISchema{Type: "string"},
+	}
+
+	return &OpenAPISchema{
+		Type: "object",
+		Properties: map[string]*OpenAPISchema{
+			"type":         {Type: "string"},
+			"resourceType": {Type: "string"},
+			"links":        stringMap,
+			"actions":      stringMap,
+			"createTypes":  stringMap,
+			"pagination":   {Type: "object"},
+			"sort":         {Type: "object"},
+			"filters":      {Type: "object"},
+			"data": {
+				Type:  "array",
+				Items: ref(schema.ID),
+			},
+		},
+	}
+}
+
+func (b *openAPIBuilder) typeSchema(schema *types.Schema) *OpenAPISchema {
+	result := &OpenAPISchema{
+		Type:       "object",
+		Properties: map[string]*OpenAPISchema{},
+	}
+
+	for name, field := range schema.ResourceFields {
+		result.Properties[name] = b.fieldSchema(field)
+		if field.Required {
+			result.Required = append(result.Required, name)
+		}
+	}
+	sort.Strings(result.Required)
+
+	if len(schema.ResourceMethods) > 0 || len(schema.CollectionMethods) > 0 {
+		stringMap := &OpenAPISchema{
+			Type:                 "object",
+			ReadOnly:             true,
+			AdditionalProperties: &OpenAPISchema{Type: "string"},
+		}
+		for _, name := range []string{"id", "type", "baseType"} {
+			if _, ok := result.Properties[name]; !ok {
+				result.Properties[name] = &OpenAPISchema{Type: "string", ReadOnly: true}
+			}
+		}
+		result.Properties["links"] = stringMap
+		result.Properties["actions"] = stringMap
+	}
+
+	return result
+}
+
+func (b *openAPIBuilder) fieldSchema(field types.Field) *OpenAPISchema {
+	readOnly := !field.Create && !field.Update
+	result := b.fieldTypeSchema(field.Type)
+	if result.Ref != "" {
+		if !field.Nullable && field.Description == "" && !field.WriteOnly && !readOnly && field.Default == nil {
+			return result
+		}
+		// siblings of $ref are ignored in OpenAPI 3.0
+		result = &OpenAPISchema{AllOf: []*OpenAPISchema{result}}
+	}
+
+	result.Description = field.Description
+	result.Nullable = field.Nullable
+	result.WriteOnly = field.WriteOnly
+	result.ReadOnly = readOnly
+	result.Default = field.Default
+	result.MinLength = field.MinLength
+	result.MaxLength = field.MaxLength
+	result.Minimum = field.Min
+	result.Maximum = field.Max
+	result.Enum = field.Options
+
+	return result
+}
+
+func (b *openAPIBuilder) fieldTypeSchema(fieldType string) *OpenAPISchema {
+	switch {
+	case definition.IsArrayType(fieldType):
+		return &OpenAPISchema{
+			Type:  "array",
+			Items: b.fieldTypeSchema(definition.SubType(fieldType)),
+		}
+	case definition.IsMapType(fieldType):
+		return &OpenAPISchema{
+			Type:                 "object",
+			AdditionalProperties: b.fieldTypeSchema(definition.SubType(fieldType)),
+		}
+	case definition.IsReferenceType(fieldType):
+		return &OpenAPISchema{Type: "string"}
+	}
+
+	switch fieldType {
+	case "string", "enum", "dnsLabel", "dnsLabelRestricted", "hostname", "reference":
+		return &OpenAPISchema{Type: "string"}
+	case "password":
+		return &OpenAPISchema{Type: "string", Format: "password"}
+	case "base64":
+		return &OpenAPISchema{Type: "string", Format: "byte"}
+	case "date":
+		return &OpenAPISchema{Type: "string", Format: "date-time"}
+	case "int":
+		return &OpenAPISchema{Type: "integer", Format: "int64"}
+	case "float":
+		return &OpenAPISchema{Type: "number"}
+	case "boolean":
+		return &OpenAPISchema{Type: "boolean"}
+	case "intOrString":
+		return &OpenAPISchema{OneOf: []*OpenAPISchema{{Type: "integer"}, {Type: "string"}}}
+	case "json":
+		return &OpenAPISchema{}
+	}
+
+	schema, ok := b.schemas[fieldType]
+	if !ok && b.apiContext != nil {
+		// builtin types such as the input of the bulk action are added once used
+		schema = b.apiContext.Schemas.Schema(&Version, fieldType)
+		ok = schema != nil && len(schema.ResourceMethods) == 0 && len(schema.CollectionMethods) == 0
+	}
+	if !ok {
+		// other types outside of this version are not part of the document
+		return &OpenAPISchema{Type: "object"}
+	}
+	if (len(schema.ResourceMethods) > 0 || len(schema.CollectionMethods) > 0) && !b.visible(schema) {
+		// neither are resources the request has no access to
+		return &OpenAPISchema{Type: "object"}
+	}
+	b.addComponent(schema)
+	return ref(fieldType)
+}
@@ -75,11 +75,93 @@ var (
 		Store:     NewAPIRootStore(nil),
 	}
 
+	OpenAPI = types.Schema{
+		ID:                "openapi",
+		PluralName:        "openapi",
+		Version:           Version,
+		CollectionMethods: []string{"GET"},
+		ResourceMethods:   []string{},
+		ResourceFields:    map[string]types.Field{},
+		ListHandler:       OpenAPIHandler,
+	}
+
+	BulkOperation = types.Schema{
+		ID:                "bulkOperation",
+		Version:           Version,
+		ResourceMethods:   []string{},
+		CollectionMethods: []string{},
+		ResourceFields: map[string]types.Field{
+			"op":   {Type: "enum", Options: []string{"create", "update", "delete"}, Required: true},
+			"id":   {Type: "string"},
+			"data": {Type: "map[json]"},
+		},
+	}
+
+	BulkInput = types.Schema{
+		ID:                "bulkInput",
+		Version:           Version,
+		ResourceMethods:   []string{},
+		CollectionMethods: []string{},
+		ResourceFields: map[string]types.Field{
+			"operations": {Type: "array[bulkOperation]", Required: true},
+			"atomic":     {Type: "boolean"},
+		},
+	}
+
+	BulkOutput = types.Schema{
+		ID:                "bulkOutput",
+		Version:           Version,
+		ResourceMethods:   []string{},
+		CollectionMethods: []string{},
+		ResourceFields: map[string]types.Field{
+			"results": {Type: "array[json]"},
+		},
+	}
+
+	Summary = types.Schema{
+		ID:                "summary",
+		Version:           Version,
+		ResourceMethods:   []string{},
+		CollectionMethods: []string{},
+		ResourceFields: map[string]types.Field{
+			"resourceType": {Type: "string"},
+			"total":        {Type: "int"},
+			"counts":       {Type: "map[json]"},
+		},
+	}
+
+	// Operation is the schema of the operations tracking asynchronous actions. It isn't a builtin schema of
+	// its own, the server adds it to every version with asynchronous actions.
+	Operation = types.Schema{
+		ID:                "operation",
+		Version:           Version,
+		CollectionMethods: []string{"GET"},
+		ResourceMethods:   []string{"GET"},
+		ResourceFields: map[string]types.Field{
+			"action":       {Type: "string"},
+			"resourceType": {Type: "string"},
+			"resourceId":   {Type: "string", Nullable: true},
+			"status":       {Type: "enum", Options: []string{"running", "succeeded", "failed"}},
+			"progress":     {Type: "int"},
+			"message":      {Type: "string", Nullable: true},
+			"result":       {Type: "json", Nullable: true},
+			"resultType":   {Type: "string", Nullable: true},
+			"error":        {Type: "map[json]", Nullable: true},
+			"created":      {Type: "date"},
+			"finished":     {Type: "date", Nullable: true},
+		},
+	}
+
 	Schemas = types.NewSchemas().
 		AddSchema(Schema).
 		AddSchema(Error).
 		AddSchema(Collection).
-		AddSchema(APIRoot)
+		AddSchema(APIRoot).
+		AddSchema(OpenAPI).
+		AddSchema(BulkOperation).
+		AddSchema(BulkInput).
+		AddSchema(BulkOutput).
+		AddSchema(Summary)
 )
 
 func apiVersionFromMap(schemas *types.Schemas, apiVersion map[string]interface{}) types.APIVersion {
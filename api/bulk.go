@@ -0,0 +1,251 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/rancher/norman/api/builtin"
+	"github.com/rancher/norman/api/writer"
+	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/parse"
+	"github.com/rancher/norman/types"
+	"github.com/rancher/norman/types/convert"
+	"github.com/rancher/norman/types/slice"
+)
+
+const (
+	// BulkAction is the collection action of the schemas setting Bulk, running a list of create, update
+	// and delete operations, unless the schema defines a collection action of the same name.
+	BulkAction = "bulk"
+	// MaxBulkOperations is the number of operations a bulk request can run
+	MaxBulkOperations = 100
+)
+
+// bulkAction is the collection action advertising bulk operations on the schemas that can be changed
+var bulkAction = types.Action{Input: builtin.BulkInput.ID, Output: builtin.BulkOutput.ID}
+
+// preconditionHeaders apply to the bulk request, not to each of its operations
+var preconditionHeaders = []string{"If-Match", "If-None-Match", "If-Modified-Since", "If-Unmodified-Since"}
+
+var bulkMethods = map[string]string{
+	"create": http.MethodPost,
+	"update": http.MethodPut,
+	"delete": http.MethodDelete,
+}
+
+type bulkInput struct {
+	Operations []bulkOperation `json:"operations"`
+	// Atomic stops at the first failed operation and deletes the resources created before it. Updates and
+	// deletes done before it are not reverted.
+	Atomic bool `json:"atomic"`
+}
+
+type bulkOperation struct {
+	Op   string                 `json:"op"`
+	ID   string                 `json:"id"`
+	Data map[string]interface{} `json:"data"`
+}
+
+type bulkCreated struct {
+	apiContext *types.APIContext
+	item       map[string]interface{}
+}
+
+// capturingWriter captures a response instead of writing it, for bulk operations and asynchronous actions
+type capturingWriter struct {
+	code int
+	obj  interface{}
+}
+
+func (b *capturingWriter) Write(apiContext *types.APIContext, code int, obj interface{}) {
+	b.code = code
+	b.obj = obj
+}
+
+func isBulk(apiRequest *types.APIContext) bool {
+	if apiRequest.Schema == nil || !apiRequest.Schema.Bulk || apiRequest.Action != BulkAction || apiRequest.ID != "" ||
+		apiRequest.Link != "" || apiRequest.Method != http.MethodPost {
+		return false
+	}
+	action, ok := apiRequest.Schema.CollectionActions[BulkAction]
+	return !ok || action == bulkAction
+}
+
+// addBulkAction advertises the bulk action on schema if it sets Bulk, can be changed and doesn't define
+// the action
+func addBulkAction(schema *types.Schema) {
+	if !schema.Bulk {
+		return
+	}
+	if _, ok := schema.CollectionActions[BulkAction]; ok {
+		return
+	}
+	if !slice.ContainsString(schema.CollectionMethods, http.MethodPost) &&
+		!slice.ContainsString(schema.ResourceMethods, http.MethodPut) &&
+		!slice.ContainsString(schema.ResourceMethods, http.MethodDelete) {
+		return
+	}
+
+	// the actions may be shared with the schema the caller added
+	actions := make(map[string]types.Action, len(schema.CollectionActions)+1)
+	for name, action := range schema.CollectionActions {
+		actions[name] = action
+	}
+	actions[BulkAction] = bulkAction
+	schema.CollectionActions = actions
+}
+
+// handleBulk runs each operation of the request body as if it was a request of its own, going through the
+// same method validation, interceptors, access control, handlers, validators and store. The response lists
+// the result of each operation.
+func (s *Server) handleBulk(apiRequest *types.APIContext) error {
+	body, err := parse.ReadBody(apiRequest.Request)
+	if err != nil {
+		return err
+	}
+
+	input := bulkInput{}
+	if err := convert.ToObj(body, &input); err != nil {
+		return httperror.NewAPIError(httperror.InvalidBodyContent, fmt.Sprintf("Failed to parse operations: %v", err))
+	}
+	if len(input.Operations) > MaxBulkOperations {
+		return httperror.NewFieldAPIError(httperror.MaxLimitExceeded, "operations",
+			fmt.Sprintf("A bulk request can run at most %d operations", MaxBulkOperations))
+	}
+
+	for i, operation := range input.Operations {
+		method, ok := bulkMethods[operation.Op]
+		if !ok {
+			return httperror.NewFieldAPIError(httperror.InvalidOption, fmt.Sprintf("operations[%d].op", i),
+				fmt.Sprintf("Invalid operation %q", operation.Op))
+		}
+		if (method == http.MethodPost) != (operation.ID == "") {
+			return httperror.NewFieldAPIError(httperror.InvalidBodyContent, fmt.Sprintf("operations[%d].id", i),
+				"id is required for update and delete, and not allowed for create")
+		}
+	}
+
+	var (
+		results []interface{}
+		created []bulkCreated
+		failed  bool
+	)
+	for _, operation := range input.Operations {
+		operationContext, err := s.bulkOperation(apiRequest, operation)
+		if err != nil {
+			return err
+		}
+
+		result := &capturingWriter{}
+		operationContext.ResponseWriter = result
+		auditor := s.startAudit(operationContext)
+		s.runBulkOperation(operationContext)
+		auditor.finish(result.code)
+
+		item := map[string]interface{}{
+			"status": result.code,
+		}
+		data, err := renderResult(operationContext, result.obj)
+		if err != nil {
+			return err
+		}
+		if result.code >= http.StatusBadRequest {
+			item["error"] = data
+			failed = true
+		} else {
+			item["data"] = data
+			if id := convert.ToString(convert.ToMapInterface(result.obj)["id"]); operation.Op == "create" && id != "" {
+				operationContext.ID = id
+				created = append(created, bulkCreated{apiContext: operationContext, item: item})
+			}
+		}
+		results = append(results, item)
+
+		if failed && input.Atomic {
+			break
+		}
+	}
+
+	// a dry run didn't create anything to roll back
+	if failed && input.Atomic && apiRequest.Option("dryRun") != "true" {
+		for _, c := range created {
+			_, err := c.apiContext.Schema.Store.Delete(c.apiContext, c.apiContext.Schema, c.apiContext.ID)
+			c.item["rolledBack"] = err == nil
+		}
+	}
+
+	apiRequest.WriteResponse(http.StatusOK, map[string]interface{}{
+		"type":    "/meta/schemas/" + builtin.BulkOutput.ID,
+		"results": results,
+	})
+	return nil
+}
+
+// runBulkOperation handles the request of an operation like handle does, writing the error it failed with
+// to the context that reached dispatch
+func (s *Server) runBulkOperation(operationContext *types.APIContext) {
+	dispatched := operationContext
+	err := parse.ValidateMethod(operationContext)
+	if err == nil {
+		err = chain(s.Interceptors, func(apiRequest *types.APIContext) error {
+			dispatched = apiRequest
+			return s.dispatch(apiRequest)
+		})(operationContext)
+	}
+	if err != nil {
+		s.handleError(dispatched, err)
+	}
+}
+
+// bulkOperation returns the context of a request doing the operation
+func (s *Server) bulkOperation(apiRequest *types.APIContext, operation bulkOperation) (*types.APIContext, error) {
+	content, err := json.Marshal(operation.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	req := apiRequest.Request.Clone(apiRequest.Request.Context())
+	req.Method = bulkMethods[operation.Op]
+	req.Body = io.NopCloser(bytes.NewReader(content))
+	req.ContentLength = int64(len(content))
+	req.Header.Set("Content-Type", "application/json")
+	for _, header := range preconditionHeaders {
+		req.Header.Del(header)
+	}
+	req.Form = nil
+	req.PostForm = nil
+	req.MultipartForm = nil
+
+	operationContext := *apiRequest
+	operationContext.Request = req
+	operationContext.Method = req.Method
+	operationContext.Action = ""
+	operationContext.ID = operation.ID
+	operationContext.Pagination = nil
+	return &operationContext, nil
+}
+
+// renderResult renders a resource or error the way the JSON response writer does
+func renderResult(apiContext *types.APIContext, obj interface{}) (interface{}, error) {
+	if obj == nil {
+		return nil, nil
+	}
+
+	jsonWriter := writer.EncodingResponseWriter{
+		ContentType: "application/json",
+		Encoder:     types.JSONEncoder,
+	}
+	buffer := &bytes.Buffer{}
+	if err := jsonWriter.Body(apiContext, buffer, obj); err != nil {
+		return nil, err
+	}
+	if buffer.Len() == 0 {
+		return nil, nil
+	}
+
+	var result interface{}
+	return result, json.Unmarshal(buffer.Bytes(), &result)
+}
@@ -20,6 +20,10 @@ func CreateHandler(apiContext *types.APIContext, next types.RequestHandler) erro
 		return httperror.NewAPIError(httperror.NotFound, "no store found")
 	}
 
+	if err := checkDryRun(apiContext, store); err != nil {
+		return err
+	}
+
 	data, err = store.Create(apiContext, apiContext.Schema, data)
 	if err != nil {
 		return err
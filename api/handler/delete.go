@@ -13,7 +13,19 @@ func DeleteHandler(request *types.APIContext, next types.RequestHandler) error {
 		return httperror.NewAPIError(httperror.NotFound, "no store found")
 	}
 
-	obj, err := store.Delete(request, request.Schema, request.ID)
+	if err := CheckIfMatch(request); err != nil {
+		return err
+	}
+
+	var (
+		obj map[string]interface{}
+		err error
+	)
+	if dryRun(request, store) {
+		obj, err = store.ByID(request, request.Schema, request.ID)
+	} else {
+		obj, err = store.Delete(request, request.Schema, request.ID)
+	}
 	if err != nil {
 		return err
 	}
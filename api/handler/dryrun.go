@@ -0,0 +1,21 @@
+package handler
+
+import (
+	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/types"
+)
+
+// dryRun returns true if the request only previews its change because of the _dryRun=true option and
+// the store can't do that itself
+func dryRun(apiContext *types.APIContext, store types.Store) bool {
+	return apiContext.Option("dryRun") == "true" && !types.SupportsDryRun(store)
+}
+
+// checkDryRun fails creates and updates previewed on a store that can't do a dry run. Echoing the input
+// would skip the mappers and defaults of the store, so it isn't the object the change would produce.
+func checkDryRun(apiContext *types.APIContext, store types.Store) error {
+	if dryRun(apiContext, store) {
+		return httperror.NewAPIError(httperror.NotImplemented, "dry run is not supported for "+apiContext.Schema.ID)
+	}
+	return nil
+}
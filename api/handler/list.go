@@ -21,6 +21,9 @@ func ListHandler(request *types.APIContext, next types.RequestHandler) error {
 
 	if request.ID == "" {
 		opts := parse.QueryOptions(request, request.Schema)
+		if fields, ok := parse.Summary(request); ok {
+			return SummaryHandler(request, &opts, fields)
+		}
 		// Save the pagination on the context so it's not reset later
 		request.Pagination = opts.Pagination
 		data, err = store.List(request, request.Schema, &opts)
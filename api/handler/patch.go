@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/parse"
+	"github.com/rancher/norman/types"
+	jsonpatch "gopkg.in/evanphx/json-patch.v4"
+)
+
+// PatchedBody applies the JSON patch or JSON merge patch of a PATCH request to the current API
+// representation of the resource and returns the complete patched resource.
+func PatchedBody(apiContext *types.APIContext) (map[string]interface{}, error) {
+	patch, patchType, err := parse.ReadPatch(apiContext.Request)
+	if err != nil {
+		return nil, err
+	}
+
+	store := apiContext.Schema.Store
+	if store == nil {
+		return nil, httperror.NewAPIError(httperror.NotFound, "no store found")
+	}
+
+	existing, err := store.ByID(apiContext, apiContext.Schema, apiContext.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	original, err := json.Marshal(existing)
+	if err != nil {
+		return nil, err
+	}
+
+	var patched []byte
+	if patchType == types.JSONPatchType {
+		operations, err := jsonpatch.DecodePatch(patch)
+		if err != nil {
+			return nil, httperror.NewAPIError(httperror.InvalidBodyContent, fmt.Sprintf("Failed to parse patch: %v", err))
+		}
+		patched, err = operations.Apply(original)
+		if err != nil {
+			return nil, httperror.NewAPIError(httperror.InvalidBodyContent, fmt.Sprintf("Failed to apply patch: %v", err))
+		}
+	} else {
+		patched, err = jsonpatch.MergePatch(original, patch)
+		if err != nil {
+			return nil, httperror.NewAPIError(httperror.InvalidBodyContent, fmt.Sprintf("Failed to apply patch: %v", err))
+		}
+	}
+
+	data := map[string]interface{}{}
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.UseNumber()
+	if err := decoder.Decode(&data); err != nil {
+		return nil, httperror.NewAPIError(httperror.InvalidBodyContent, fmt.Sprintf("Failed to parse patched resource: %v", err))
+	}
+
+	return data, nil
+}
@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/types"
+)
+
+// CheckIfMatch returns a conflict if the request has an If-Match header that doesn't match the current
+// ETag of the resource. Resources without an ETag aren't versioned and always match.
+func CheckIfMatch(apiContext *types.APIContext) error {
+	ifMatch := apiContext.Request.Header.Get("If-Match")
+	if ifMatch == "" {
+		return nil
+	}
+
+	store := apiContext.Schema.Store
+	if store == nil {
+		return httperror.NewAPIError(httperror.NotFound, "no store found")
+	}
+
+	existing, err := store.ByID(apiContext, apiContext.Schema, apiContext.ID)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return httperror.NewAPIError(httperror.NotFound, "")
+	}
+
+	if etag := types.ETag(existing); etag != "" && !types.ETagMatches(ifMatch, etag) {
+		return httperror.NewAPIError(httperror.Conflict, "the resource has been modified, expected "+ifMatch+" but is "+etag)
+	}
+	return nil
+}
@@ -4,7 +4,6 @@ import (
 	"sort"
 
 	"github.com/rancher/norman/types"
-	"github.com/rancher/norman/types/convert"
 )
 
 func QueryFilter(opts *types.QueryOptions, schema *types.Schema, data []map[string]interface{}) []map[string]interface{} {
@@ -13,28 +12,57 @@ func QueryFilter(opts *types.QueryOptions, schema *types.Schema, data []map[stri
 
 func ApplyQueryOptions(options *types.QueryOptions, schema *types.Schema, data []map[string]interface{}) []map[string]interface{} {
 	data = ApplyQueryConditions(options.Conditions, schema, data)
-	data = ApplySort(options.Sort, data)
+	data = ApplySchemaSort(options.Sort, schema, data)
 	return ApplyPagination(options.Pagination, data)
 }
 
+// ApplySort sorts data by every field of sortOpts in turn, comparing values as strings
 func ApplySort(sortOpts types.Sort, data []map[string]interface{}) []map[string]interface{} {
-	name := sortOpts.Name
-	if name == "" {
-		name = "id"
-	}
+	return ApplySchemaSort(sortOpts, nil, data)
+}
 
-	sort.Slice(data, func(i, j int) bool {
-		left, right := i, j
-		if sortOpts.Order == types.DESC {
-			left, right = j, i
+// ApplySchemaSort sorts data by every field of sortOpts in turn, comparing values according to the field
+// type of schema, or as strings without a schema. The sort is stable so items comparing equal keep the
+// order of the store.
+func ApplySchemaSort(sortOpts types.Sort, schema *types.Schema, data []map[string]interface{}) []map[string]interface{} {
+	fields := sortOpts.Fields
+	if len(fields) == 0 {
+		name := sortOpts.Name
+		if name == "" {
+			name = "id"
 		}
+		fields = []types.SortField{{Name: name, Order: sortOpts.Order}}
+	}
 
-		return convert.ToString(data[left][name]) < convert.ToString(data[right][name])
+	sort.SliceStable(data, func(i, j int) bool {
+		for _, field := range fields {
+			var schemaField types.Field
+			if schema != nil {
+				schemaField = schema.ResourceFields[field.Name]
+			}
+			result := types.CompareFieldValues(schemaField, sortValue(schemaField, data[i], field.Name),
+				sortValue(schemaField, data[j], field.Name))
+			if field.Order == types.DESC {
+				result = -result
+			}
+			if result != 0 {
+				return result < 0
+			}
+		}
+		return false
 	})
 
 	return data
 }
 
+func sortValue(field types.Field, data map[string]interface{}, name string) interface{} {
+	value := data[name]
+	if value == nil {
+		return field.Default
+	}
+	return value
+}
+
 func ApplyQueryConditions(conditions []*types.QueryCondition, schema *types.Schema, data []map[string]interface{}) []map[string]interface{} {
 	var result []map[string]interface{}
 
@@ -53,7 +81,7 @@ outer:
 }
 
 func ApplyPagination(pagination *types.Pagination, data []map[string]interface{}) []map[string]interface{} {
-	if pagination == nil || pagination.Limit == nil {
+	if pagination == nil || pagination.Limit == nil || pagination.Native {
 		return data
 	}
 
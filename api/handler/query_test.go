@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/rancher/norman/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplySort(t *testing.T) {
+	schema := &types.Schema{
+		ResourceFields: map[string]types.Field{
+			"name":     {Type: "string"},
+			"replicas": {Type: "int"},
+			"created":  {Type: "date"},
+			"state":    {Type: "enum", Options: []string{"active", "updating", "removed"}},
+		},
+	}
+
+	data := func() []map[string]interface{} {
+		return []map[string]interface{}{
+			{"id": "a", "name": "a", "replicas": int64(10), "created": "2024-01-02T00:00:00Z", "state": "removed"},
+			{"id": "b", "name": "b", "replicas": int64(9), "created": "2024-01-01T00:00:00+02:00", "state": "active"},
+			{"id": "c", "name": "c", "replicas": int64(10), "created": "2023-12-31T23:00:00Z", "state": "updating"},
+			{"id": "d", "name": "d", "state": "active"},
+		}
+	}
+
+	ids := func(data []map[string]interface{}) []string {
+		var result []string
+		for _, item := range data {
+			result = append(result, item["id"].(string))
+		}
+		return result
+	}
+
+	tests := []struct {
+		name     string
+		sort     types.Sort
+		expected []string
+	}{
+		{
+			name:     "default to id",
+			sort:     types.Sort{Order: types.DESC},
+			expected: []string{"d", "c", "b", "a"},
+		},
+		{
+			name:     "numbers",
+			sort:     types.Sort{Name: "replicas"},
+			expected: []string{"d", "b", "a", "c"},
+		},
+		{
+			name:     "dates",
+			sort:     types.Sort{Name: "created"},
+			expected: []string{"d", "b", "c", "a"},
+		},
+		{
+			name:     "enum option order",
+			sort:     types.Sort{Name: "state"},
+			expected: []string{"b", "d", "c", "a"},
+		},
+		{
+			name: "multiple fields",
+			sort: types.Sort{
+				Fields: []types.SortField{
+					{Name: "replicas", Order: types.DESC},
+					{Name: "state", Order: types.ASC},
+				},
+			},
+			expected: []string{"c", "a", "b", "d"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ids(ApplySchemaSort(tt.sort, schema, data())))
+		})
+	}
+
+	assert.Equal(t, []string{"d", "a", "c", "b"}, ids(ApplySort(types.Sort{Name: "replicas"}, data())),
+		"values are compared as strings without a schema")
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/rancher/norman/api/builtin"
+	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/types"
+	"github.com/rancher/norman/types/convert"
+)
+
+// SummaryHandler counts the resources of the collection matching the conditions of the request, grouped by
+// each of the fields. The resources are counted as returned by the store and never built for output.
+func SummaryHandler(request *types.APIContext, opts *types.QueryOptions, fields []string) error {
+	store := request.Schema.Store
+	if store == nil {
+		return httperror.NewAPIError(httperror.NotFound, "no store found")
+	}
+
+	for _, field := range fields {
+		if _, ok := request.Schema.CollectionFilters[field]; !ok {
+			return httperror.NewAPIError(httperror.InvalidOption, "summary field "+field+" is not filterable")
+		}
+	}
+
+	opts.Pagination = nil
+	data, err := store.List(request, request.Schema, opts)
+	if err != nil {
+		return err
+	}
+
+	summary := &types.Summary{
+		Type:         builtin.Summary.ID,
+		ResourceType: request.Type,
+		Total:        int64(len(data)),
+		Counts:       map[string]map[string]int64{},
+	}
+	for _, field := range fields {
+		fieldCounts := map[string]int64{}
+		for _, item := range data {
+			fieldCounts[convert.ToString(item[field])]++
+		}
+		summary.Counts[field] = fieldCounts
+	}
+
+	request.WriteResponse(http.StatusOK, summary)
+	return nil
+}
@@ -0,0 +1,21 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummaryHandlerNoStore(t *testing.T) {
+	apiContext := &types.APIContext{
+		Type:   "hobbit",
+		Schema: &types.Schema{ID: "hobbit"},
+	}
+
+	err := SummaryHandler(apiContext, &types.QueryOptions{}, nil)
+	if assert.Error(t, err) {
+		assert.Equal(t, httperror.NotFound, err.(*httperror.APIError).Code)
+	}
+}
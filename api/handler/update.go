@@ -8,6 +8,10 @@ import (
 )
 
 func UpdateHandler(apiContext *types.APIContext, next types.RequestHandler) error {
+	if err := CheckIfMatch(apiContext); err != nil {
+		return err
+	}
+
 	data, err := ParseAndValidateBody(apiContext, false)
 	if err != nil {
 		return err
@@ -18,6 +22,10 @@ func UpdateHandler(apiContext *types.APIContext, next types.RequestHandler) erro
 		return httperror.NewAPIError(httperror.NotFound, "no store found")
 	}
 
+	if err := checkDryRun(apiContext, store); err != nil {
+		return err
+	}
+
 	data, err = store.Update(apiContext, apiContext.Schema, data, apiContext.ID)
 	if err != nil {
 		return err
@@ -1,13 +1,23 @@
 package handler
 
 import (
+	"net/http"
+
 	"github.com/rancher/norman/parse"
 	"github.com/rancher/norman/parse/builder"
 	"github.com/rancher/norman/types"
 )
 
 func ParseAndValidateBody(apiContext *types.APIContext, create bool) (map[string]interface{}, error) {
-	data, err := parse.Body(apiContext.Request)
+	var (
+		data map[string]interface{}
+		err  error
+	)
+	if apiContext.Method == http.MethodPatch {
+		data, err = PatchedBody(apiContext)
+	} else {
+		data, err = parse.Body(apiContext.Request)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,24 @@
+package api
+
+import "github.com/rancher/norman/types"
+
+// Next continues handling a request with the next interceptor, or dispatches it after the last one
+type Next func(apiRequest *types.APIContext) error
+
+// Interceptor runs after a request is parsed and before it is dispatched to the handler of its schema.
+// It short-circuits the request by writing a response or returning an error without calling next, and
+// decorates the response by replacing apiRequest.ResponseWriter before calling next. Errors are written
+// by the error handler of the schema, like handler errors.
+type Interceptor func(apiRequest *types.APIContext, next Next) error
+
+// chain returns the Next running interceptors in order before last
+func chain(interceptors []Interceptor, last Next) Next {
+	next := last
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, inner := interceptors[i], next
+		next = func(apiRequest *types.APIContext) error {
+			return interceptor(apiRequest, inner)
+		}
+	}
+	return next
+}
@@ -3,7 +3,9 @@ package api
 import (
 	"net/http"
 	"runtime/debug"
+	"strconv"
 	"sync"
+	"time"
 
 	"github.com/rancher/norman/api/access"
 	"github.com/rancher/norman/api/builtin"
@@ -13,9 +15,16 @@ import (
 	"github.com/rancher/norman/httperror"
 	ehandler "github.com/rancher/norman/httperror/handler"
 	"github.com/rancher/norman/parse"
+	"github.com/rancher/norman/pkg/audit"
+	"github.com/rancher/norman/pkg/metrics"
+	"github.com/rancher/norman/pkg/tracing"
+	"github.com/rancher/norman/store/operation"
 	"github.com/rancher/norman/store/wrapper"
 	"github.com/rancher/norman/types"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type StoreWrapper func(types.Store) types.Store
@@ -35,6 +44,17 @@ type Server struct {
 	URLParser                   parse.URLParser
 	Defaults                    Defaults
 	AccessControl               types.AccessControl
+	// TracerProvider creates the spans of requests, the global tracer provider is used if nil
+	TracerProvider trace.TracerProvider
+	// Interceptors run in order around the dispatch of every parsed request
+	Interceptors []Interceptor
+	// Operations keeps the operations of asynchronous actions
+	Operations *operation.Store
+	// AuditSink receives an event for every create, update, delete and action request if set
+	AuditSink audit.Sink
+
+	// operationPaths holds the paths of the versions serving the operations of asynchronous actions
+	operationPaths map[string]bool
 }
 
 type Defaults struct {
@@ -83,6 +103,7 @@ func NewAPIServer() *Server {
 		StoreWrapper: wrapper.Wrap,
 		URLParser:    parse.DefaultURLParser,
 		QueryFilter:  handler.QueryFilter,
+		Operations:   operation.NewStore(),
 	}
 
 	s.Schemas.AddHook = s.setupDefaults
@@ -124,8 +145,21 @@ func (s *Server) AddSchemas(schemas *types.Schemas) error {
 		}
 	})
 
+	var asyncVersions []types.APIVersion
 	for _, schema := range schemas.Schemas() {
+		if schema.ID == operation.SchemaID && s.operationPaths[schema.Version.Path] {
+			return operationCollision(schema.Version)
+		}
 		s.Schemas.AddSchema(*schema)
+		if s.Operations != nil && hasAsyncAction(schema) {
+			asyncVersions = append(asyncVersions, schema.Version)
+		}
+	}
+
+	for _, version := range asyncVersions {
+		if err := s.addOperationSchema(version); err != nil {
+			return err
+		}
 	}
 
 	return s.Schemas.Err()
@@ -164,102 +198,169 @@ func (s *Server) setupDefaults(schema *types.Schema) {
 		schema.ErrorHandler = s.Defaults.ErrorHandler
 	}
 
+	if !schema.Version.Equals(&builtin.Version) {
+		addBulkAction(schema)
+	}
+
 	if schema.Store != nil && s.StoreWrapper != nil {
 		schema.Store = s.StoreWrapper(schema.Store)
 	}
 }
 
 func (s *Server) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	start := time.Now()
+	statusRW := &statusWriter{ResponseWriter: rw}
+	schemaID := ""
+	var auditor *auditWriter
+
+	ctx, span := tracing.Tracer(s.TracerProvider).Start(tracing.Extract(req.Context(), req), "norman.request",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("http.request.method", req.Method),
+			attribute.String("url.path", req.URL.Path),
+		))
+	req = req.WithContext(ctx)
+
+	defer func() {
+		status := statusRW.Status()
+		metrics.ObserveRequest(schemaID, req.Method, strconv.Itoa(status), start)
+		auditor.finish(status)
+
+		span.SetAttributes(
+			attribute.String("norman.schema", schemaID),
+			attribute.Int("http.response.status_code", status),
+		)
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+		span.End()
+	}()
+
 	defer func() {
 		if err := recover(); err != nil && err != http.ErrAbortHandler {
 			logrus.Error("Panic serving api request: \n" + string(debug.Stack()))
-			rw.WriteHeader(http.StatusInternalServerError)
+			statusRW.WriteHeader(http.StatusInternalServerError)
 		}
 	}()
 
-	if apiResponse, err := s.handle(rw, req); err != nil {
+	apiResponse, auditor, err := s.handle(statusRW, req)
+	if apiResponse != nil {
+		schemaID = apiResponse.Type
+	}
+	if err != nil {
 		s.handleError(apiResponse, err)
 	}
 }
 
-func (s *Server) handle(rw http.ResponseWriter, req *http.Request) (*types.APIContext, error) {
+// handle parses and dispatches a request. The audit writer of the request is returned apart from the
+// context, as interceptors may wrap it in a response writer of their own.
+func (s *Server) handle(rw http.ResponseWriter, req *http.Request) (*types.APIContext, *auditWriter, error) {
 	apiRequest, err := s.Parser(rw, req)
 	if err != nil {
-		return apiRequest, err
+		return apiRequest, nil, err
 	}
 
 	if err := CheckCSRF(apiRequest); err != nil {
-		return apiRequest, err
+		return apiRequest, nil, err
+	}
+
+	// bulk operations are audited one by one
+	var auditor *auditWriter
+	if !isBulk(apiRequest) {
+		auditor = s.startAudit(apiRequest)
+	}
+
+	// the context reaching dispatch is the one errors are reported with, interceptors may replace it
+	dispatched := apiRequest
+	err = chain(s.Interceptors, func(apiRequest *types.APIContext) error {
+		dispatched = apiRequest
+		return s.dispatch(apiRequest)
+	})(apiRequest)
+	return dispatched, auditor, err
+}
+
+// dispatch calls the handler of a parsed request
+func (s *Server) dispatch(apiRequest *types.APIContext) error {
+	if isBulk(apiRequest) {
+		return s.handleBulk(apiRequest)
 	}
 
 	action, err := ValidateAction(apiRequest)
 	if err != nil {
-		return apiRequest, err
+		return err
 	}
 
 	if apiRequest.Schema == nil {
-		return apiRequest, nil
+		return nil
 	}
 
 	if action == nil && apiRequest.Type != "" {
-		var handler types.RequestHandler
-		var nextHandler types.RequestHandler
-		if apiRequest.Link == "" {
-			switch apiRequest.Method {
-			case http.MethodGet:
-				if apiRequest.ID == "" {
-					if err := apiRequest.AccessControl.CanList(apiRequest, apiRequest.Schema); err != nil {
-						return apiRequest, err
-					}
-				} else {
-					if err := apiRequest.AccessControl.CanGet(apiRequest, apiRequest.Schema); err != nil {
-						return apiRequest, err
-					}
-				}
-				handler = apiRequest.Schema.ListHandler
-				nextHandler = s.Defaults.ListHandler
-			case http.MethodPost:
-				if err := apiRequest.AccessControl.CanCreate(apiRequest, apiRequest.Schema); err != nil {
-					return apiRequest, err
-				}
-				handler = apiRequest.Schema.CreateHandler
-				nextHandler = s.Defaults.CreateHandler
-			case http.MethodPut:
-				if err := apiRequest.AccessControl.CanUpdate(apiRequest, nil, apiRequest.Schema); err != nil {
-					return apiRequest, err
+		return s.handleRequest(apiRequest)
+	} else if action != nil {
+		return s.handleAction(action, apiRequest)
+	}
+
+	return nil
+}
+
+// handleRequest checks access to and calls the handler for the method of a request without an action
+func (s *Server) handleRequest(apiRequest *types.APIContext) error {
+	var handler types.RequestHandler
+	var nextHandler types.RequestHandler
+	if apiRequest.Link == "" {
+		switch apiRequest.Method {
+		case http.MethodGet:
+			if apiRequest.ID == "" {
+				if err := apiRequest.AccessControl.CanList(apiRequest, apiRequest.Schema); err != nil {
+					return err
 				}
-				handler = apiRequest.Schema.UpdateHandler
-				nextHandler = s.Defaults.UpdateHandler
-			case http.MethodDelete:
-				if err := apiRequest.AccessControl.CanDelete(apiRequest, nil, apiRequest.Schema); err != nil {
-					return apiRequest, err
+			} else {
+				if err := apiRequest.AccessControl.CanGet(apiRequest, apiRequest.Schema); err != nil {
+					return err
 				}
-				handler = apiRequest.Schema.DeleteHandler
-				nextHandler = s.Defaults.DeleteHandler
 			}
-		} else {
 			handler = apiRequest.Schema.ListHandler
 			nextHandler = s.Defaults.ListHandler
+		case http.MethodPost:
+			if err := apiRequest.AccessControl.CanCreate(apiRequest, apiRequest.Schema); err != nil {
+				return err
+			}
+			handler = apiRequest.Schema.CreateHandler
+			nextHandler = s.Defaults.CreateHandler
+		case http.MethodPut, http.MethodPatch:
+			if err := apiRequest.AccessControl.CanUpdate(apiRequest, nil, apiRequest.Schema); err != nil {
+				return err
+			}
+			handler = apiRequest.Schema.UpdateHandler
+			nextHandler = s.Defaults.UpdateHandler
+		case http.MethodDelete:
+			if err := apiRequest.AccessControl.CanDelete(apiRequest, nil, apiRequest.Schema); err != nil {
+				return err
+			}
+			handler = apiRequest.Schema.DeleteHandler
+			nextHandler = s.Defaults.DeleteHandler
 		}
+	} else {
+		handler = apiRequest.Schema.ListHandler
+		nextHandler = s.Defaults.ListHandler
+	}
 
-		if handler == nil {
-			return apiRequest, httperror.NewAPIError(httperror.NotFound, "")
-		}
-
-		return apiRequest, handler(apiRequest, nextHandler)
-	} else if action != nil {
-		return apiRequest, handleAction(action, apiRequest)
+	if handler == nil {
+		return httperror.NewAPIError(httperror.NotFound, "")
 	}
 
-	return apiRequest, nil
+	return handler(apiRequest, nextHandler)
 }
 
-func handleAction(action *types.Action, context *types.APIContext) error {
+func (s *Server) handleAction(action *types.Action, context *types.APIContext) error {
 	if context.ID != "" {
 		if err := access.ByID(context, context.Version, context.Type, context.ID, nil); err != nil {
 			return err
 		}
 	}
+	if action.Async && s.Operations != nil {
+		return s.handleAsyncAction(action, context)
+	}
 	return context.Schema.ActionHandler(context.Action, action, context)
 }
 
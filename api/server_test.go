@@ -1,17 +1,31 @@
 package api_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/rancher/norman/api"
 	"github.com/rancher/norman/api/builtin"
 	"github.com/rancher/norman/api/writer"
+	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/parse"
+	"github.com/rancher/norman/pkg/audit"
+	"github.com/rancher/norman/pkg/metrics"
+	"github.com/rancher/norman/store/empty"
+	"github.com/rancher/norman/store/operation"
+	"github.com/rancher/norman/types"
+	"github.com/rancher/norman/types/convert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 func TestServeHTMLEscaping(t *testing.T) {
@@ -135,3 +149,1222 @@ func sendTestRequest(url, cssURL, jssURL, apiUIVersion string) (string, error) {
 func stringGetter(val string) writer.StringGetter {
 	return func() string { return val }
 }
+
+func TestServeOpenAPI(t *testing.T) {
+	srv := newHobbitServer(t,
+		types.Schema{
+			ID:                "hobbit",
+			CollectionMethods: []string{http.MethodGet, http.MethodPost},
+			ResourceMethods:   []string{http.MethodGet, http.MethodDelete},
+			ResourceFields: map[string]types.Field{
+				"name":      {Type: "string", Required: true, Create: true},
+				"meal":      {Type: "enum", Options: []string{"breakfast", "elevenses"}},
+				"favorite":  {Type: "meal"},
+				"treasures": {Type: "hobbitCollection", Create: true, Update: true},
+			},
+			ResourceActions: map[string]types.Action{
+				"eat":  {Input: "meal"},
+				"cook": {Output: "meal", Async: true},
+			},
+			CollectionActions: map[string]types.Action{
+				"gather": {Input: "basket"},
+			},
+			CollectionFilters: map[string]types.Filter{
+				"name": {Modifiers: []types.ModifierType{types.ModifierEQ, types.ModifierIn}},
+			},
+			Bulk: true,
+		},
+		types.Schema{
+			ID: "meal",
+			ResourceFields: map[string]types.Field{
+				"size": {Type: "int"},
+			},
+		},
+		types.Schema{
+			ID: "hobbitCollection",
+			ResourceFields: map[string]types.Field{
+				"rings": {Type: "int"},
+			},
+		},
+		types.Schema{
+			ID: "basket",
+			ResourceFields: map[string]types.Field{
+				"mushrooms": {Type: "int"},
+			},
+		},
+		types.Schema{
+			ID:              "ring",
+			ResourceMethods: []string{http.MethodDelete},
+			ResourceFields: map[string]types.Field{
+				"bearer": {Type: "string"},
+			},
+		},
+		types.Schema{
+			ID: "unused",
+		})
+
+	resp := httptest.NewRecorder()
+	srv.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "https://cattle.io/v1/openapi", nil))
+	require.Equal(t, http.StatusOK, resp.Code)
+
+	doc := builtin.OpenAPIDocument{}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &doc))
+
+	require.Equal(t, builtin.OpenAPIVersion, doc.OpenAPI)
+	require.Equal(t, "https://cattle.io/v1", doc.Servers[0].URL)
+
+	collection := doc.Paths["/hobbits"]
+	require.Contains(t, collection, "get")
+	require.Contains(t, collection, "post")
+	require.Equal(t, "#/components/schemas/hobbitCollection_", collection["get"].Responses["200"].Content["application/json"].Schema.Ref,
+		"collections don't take the name of a schema")
+	require.Contains(t, doc.Components.Schemas["hobbitCollection"].Properties, "rings")
+	require.Equal(t, "#/components/schemas/error", collection["get"].Responses["default"].Content["application/json"].Schema.Ref)
+
+	var params []string
+	for _, param := range collection["get"].Parameters {
+		params = append(params, param.Name)
+	}
+	require.Subset(t, params, []string{"limit", "marker", "name", "name_in"})
+
+	resource := doc.Paths["/hobbits/{id}"]
+	require.Contains(t, resource, "get")
+	require.Contains(t, resource, "delete")
+	require.NotContains(t, resource, "put")
+
+	for path := range doc.Paths {
+		require.NotContains(t, path, "?", "paths can't hold a query string")
+	}
+
+	actions := resource["post"]
+	require.Equal(t, "actionHobbit", actions.OperationID)
+	actionParam := actions.Parameters[len(actions.Parameters)-1]
+	require.Equal(t, "action", actionParam.Name)
+	require.True(t, actionParam.Required)
+	require.Equal(t, []string{"cook", "eat"}, actionParam.Schema.Enum)
+	require.False(t, actions.RequestBody.Required, "cook has no input")
+	require.Equal(t, "#/components/schemas/meal", actions.RequestBody.Content["application/json"].Schema.Ref)
+	require.Equal(t, "#/components/schemas/operation", actions.Responses["202"].Content["application/json"].Schema.Ref)
+	require.Equal(t, "#/components/schemas/meal", actions.Actions["eat"].RequestBody.Content["application/json"].Schema.Ref)
+	require.Nil(t, actions.Actions["cook"].RequestBody)
+	require.Contains(t, doc.Paths, "/operations/{id}")
+
+	gather := collection["post"]
+	require.Equal(t, "createHobbit", gather.OperationID)
+	require.False(t, gather.Parameters[len(gather.Parameters)-1].Required, "POST creates a hobbit without an action")
+	require.Equal(t, []string{"bulk", "gather"}, gather.Parameters[len(gather.Parameters)-1].Schema.Enum)
+	require.Equal(t, []*builtin.OpenAPISchema{
+		{Ref: "#/components/schemas/hobbit"},
+		{Ref: "#/components/schemas/bulkInput"},
+		{Ref: "#/components/schemas/basket"},
+	}, gather.RequestBody.Content["application/json"].Schema.OneOf)
+	require.Equal(t, "#/components/schemas/bulkOperation", doc.Components.Schemas["bulkInput"].Properties["operations"].Items.Ref)
+	require.Contains(t, gather.Responses, "201")
+	require.Contains(t, gather.Responses, "200")
+
+	require.NotContains(t, doc.Components.Schemas, "ring", "types the request can't list or get are left out")
+	require.NotContains(t, doc.Components.Schemas, "unused")
+
+	hobbit := doc.Components.Schemas["hobbit"]
+	require.Equal(t, []string{"name"}, hobbit.Required)
+	require.Equal(t, []string{"breakfast", "elevenses"}, hobbit.Properties["meal"].Enum)
+	require.Equal(t, &builtin.OpenAPISchema{
+		AllOf:    []*builtin.OpenAPISchema{{Ref: "#/components/schemas/meal"}},
+		ReadOnly: true,
+	}, hobbit.Properties["favorite"], "read-only references keep readOnly")
+	require.Equal(t, "#/components/schemas/hobbitCollection", hobbit.Properties["treasures"].Ref)
+	require.Equal(t, "integer", doc.Components.Schemas["meal"].Properties["size"].Type)
+	require.Contains(t, doc.Components.Schemas["error"].Properties, "fieldName")
+}
+
+// hobbitVersion is the version of the schemas served by newHobbitServer
+var hobbitVersion = types.APIVersion{
+	Group:   "shire.cattle.io",
+	Version: "v1",
+	Path:    "/v1",
+}
+
+// newHobbitServer returns a server serving schemas in hobbitVersion under https://cattle.io/v1
+func newHobbitServer(t *testing.T, schemas ...types.Schema) *api.Server {
+	s := types.NewSchemas()
+	for _, schema := range schemas {
+		schema.Version = hobbitVersion
+		s.AddSchema(schema)
+	}
+	require.NoError(t, s.Err())
+
+	srv := api.NewAPIServer()
+	require.NoError(t, srv.AddSchemas(s))
+	return srv
+}
+
+type hobbitStore struct {
+	empty.Store
+	hobbits map[string]map[string]interface{}
+}
+
+func (h *hobbitStore) ByID(apiContext *types.APIContext, schema *types.Schema, id string) (map[string]interface{}, error) {
+	return h.hobbits[id], nil
+}
+
+func (h *hobbitStore) List(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions) ([]map[string]interface{}, error) {
+	var result []map[string]interface{}
+	for _, hobbit := range h.hobbits {
+		result = append(result, hobbit)
+	}
+	return result, nil
+}
+
+func (h *hobbitStore) Update(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}, id string) (map[string]interface{}, error) {
+	data["id"] = id
+	data["type"] = schema.ID
+	h.hobbits[id] = data
+	return data, nil
+}
+
+func (h *hobbitStore) Create(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}) (map[string]interface{}, error) {
+	return h.Update(apiContext, schema, data, convert.ToString(data["name"]))
+}
+
+func (h *hobbitStore) Delete(apiContext *types.APIContext, schema *types.Schema, id string) (map[string]interface{}, error) {
+	delete(h.hobbits, id)
+	return nil, nil
+}
+
+// countingStore counts the calls reading resources from its store
+type countingStore struct {
+	types.Store
+	calls int
+}
+
+func (c *countingStore) ByID(apiContext *types.APIContext, schema *types.Schema, id string) (map[string]interface{}, error) {
+	c.calls++
+	return c.Store.ByID(apiContext, schema, id)
+}
+
+func (c *countingStore) List(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions) ([]map[string]interface{}, error) {
+	c.calls++
+	return c.Store.List(apiContext, schema, opt)
+}
+
+func TestServePatch(t *testing.T) {
+	store := &hobbitStore{
+		hobbits: map[string]map[string]interface{}{
+			"frodo": {"id": "frodo", "type": "hobbit", "name": "Frodo", "meal": "breakfast", "age": int64(50)},
+		},
+	}
+
+	srv := newHobbitServer(t, types.Schema{
+		ID:                "hobbit",
+		CollectionMethods: []string{http.MethodGet},
+		ResourceMethods:   []string{http.MethodGet, http.MethodPut, http.MethodPatch},
+		ResourceFields: map[string]types.Field{
+			"name": {Type: "string", Create: true},
+			"meal": {Type: "string", Update: true, Nullable: true},
+			"age":  {Type: "int", Update: true},
+		},
+		Store: store,
+	})
+
+	patch := func(contentType, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPatch, "https://cattle.io/v1/hobbits/frodo", strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		resp := httptest.NewRecorder()
+		srv.ServeHTTP(resp, req)
+		return resp
+	}
+
+	resp := patch(types.MergePatchType, `{"meal": null, "age": 51}`)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	require.NotContains(t, store.hobbits["frodo"], "meal")
+	require.EqualValues(t, 51, store.hobbits["frodo"]["age"])
+
+	resp = patch(types.JSONPatchType, `[{"op": "add", "path": "/meal", "value": "elevenses"}, {"op": "replace", "path": "/name", "value": "Sam"}]`)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	require.Equal(t, "elevenses", store.hobbits["frodo"]["meal"])
+	require.NotContains(t, store.hobbits["frodo"], "name", "name is not updatable")
+
+	resp = patch(types.JSONPatchType, `[{"op": "remove", "path": "/height"}]`)
+	require.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+
+	resp = patch("application/json", `{"age": 52}`)
+	require.Equal(t, http.StatusUnsupportedMediaType, resp.Code)
+}
+
+func TestServeETag(t *testing.T) {
+	store := &hobbitStore{
+		hobbits: map[string]map[string]interface{}{
+			"frodo": {"id": "frodo", "type": "hobbit", "age": int64(50), types.ResourceFieldResourceVersion: "7"},
+		},
+	}
+
+	srv := newHobbitServer(t, types.Schema{
+		ID:                "hobbit",
+		CollectionMethods: []string{http.MethodGet},
+		ResourceMethods:   []string{http.MethodGet, http.MethodPut},
+		ResourceFields: map[string]types.Field{
+			"age": {Type: "int", Update: true},
+		},
+		Store: store,
+	})
+
+	serve := func(method, etagHeader, etag, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "https://cattle.io/v1/hobbits/frodo", strings.NewReader(body))
+		if etag != "" {
+			req.Header.Set(etagHeader, etag)
+		}
+		resp := httptest.NewRecorder()
+		srv.ServeHTTP(resp, req)
+		return resp
+	}
+
+	resp := serve(http.MethodGet, "", "", "")
+	require.Equal(t, http.StatusOK, resp.Code)
+	require.Equal(t, `"7"`, resp.Header().Get("ETag"))
+	require.NotContains(t, resp.Body.String(), "resourceVersion")
+
+	resp = serve(http.MethodGet, "If-None-Match", `"7"`, "")
+	require.Equal(t, http.StatusNotModified, resp.Code)
+	require.Empty(t, resp.Body.String())
+
+	resp = serve(http.MethodGet, "If-None-Match", `"6"`, "")
+	require.Equal(t, http.StatusOK, resp.Code)
+
+	resp = serve(http.MethodPut, "If-Match", `"6"`, `{"age": 51}`)
+	require.Equal(t, http.StatusConflict, resp.Code)
+	require.EqualValues(t, 50, store.hobbits["frodo"]["age"])
+
+	resp = serve(http.MethodPut, "If-Match", `"7"`, `{"age": 51}`)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	require.EqualValues(t, 51, store.hobbits["frodo"]["age"])
+}
+
+func TestServeBulk(t *testing.T) {
+	store := &hobbitStore{
+		hobbits: map[string]map[string]interface{}{
+			"frodo": {"id": "frodo", "type": "hobbit", "name": "frodo", "age": int64(50)},
+		},
+	}
+
+	var intercepted []string
+	srv := newHobbitServer(t, types.Schema{
+		ID:                "hobbit",
+		CollectionMethods: []string{http.MethodGet, http.MethodPost},
+		ResourceMethods:   []string{http.MethodGet, http.MethodPut},
+		ResourceFields: map[string]types.Field{
+			"name": {Type: "string", Create: true, Required: true},
+			"age":  {Type: "int", Create: true, Update: true},
+		},
+		Bulk:  true,
+		Store: store,
+	})
+	srv.Interceptors = append(srv.Interceptors, func(apiRequest *types.APIContext, next api.Next) error {
+		intercepted = append(intercepted, apiRequest.Method+" "+apiRequest.ID+" "+apiRequest.Request.Header.Get("If-Match"))
+		return next(apiRequest)
+	})
+
+	serve := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "https://cattle.io/v1/hobbits?action=bulk", strings.NewReader(body))
+		req.Header.Set("If-Match", `"stale"`)
+		resp := httptest.NewRecorder()
+		srv.ServeHTTP(resp, req)
+		return resp
+	}
+	bulk := func(body string) map[string]interface{} {
+		resp := serve(body)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+		result := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &result))
+		require.Equal(t, "bulkOutput", result["type"])
+		return result
+	}
+
+	result := bulk(`{"operations": [
+		{"op": "create", "data": {"name": "sam", "age": 38}},
+		{"op": "update", "id": "frodo", "data": {"age": 51}},
+		{"op": "create", "data": {"age": 1}}
+	]}`)
+	results := result["results"].([]interface{})
+	require.Len(t, results, 3)
+	require.EqualValues(t, http.StatusCreated, results[0].(map[string]interface{})["status"])
+	require.Equal(t, "sam", results[0].(map[string]interface{})["data"].(map[string]interface{})["id"])
+	require.EqualValues(t, http.StatusOK, results[1].(map[string]interface{})["status"])
+	require.EqualValues(t, http.StatusUnprocessableEntity, results[2].(map[string]interface{})["status"])
+	require.Equal(t, "MissingRequired", results[2].(map[string]interface{})["error"].(map[string]interface{})["code"])
+	require.Contains(t, store.hobbits, "sam")
+	require.EqualValues(t, 51, store.hobbits["frodo"]["age"], "the preconditions of the bulk request aren't applied to operations")
+	require.Equal(t, []string{`POST  "stale"`, "POST  ", "PUT frodo ", "POST  "}, intercepted,
+		"the bulk request and each operation go through the interceptors")
+
+	result = bulk(`{"operations": [{"op": "delete", "id": "frodo"}]}`)
+	results = result["results"].([]interface{})
+	require.EqualValues(t, http.StatusMethodNotAllowed, results[0].(map[string]interface{})["status"])
+	require.Contains(t, store.hobbits, "frodo")
+
+	result = bulk(`{"atomic": true, "operations": [
+		{"op": "create", "data": {"name": "merry"}},
+		{"op": "create", "data": {"age": 1}},
+		{"op": "create", "data": {"name": "pippin"}}
+	]}`)
+	results = result["results"].([]interface{})
+	require.Len(t, results, 2)
+	require.Equal(t, true, results[0].(map[string]interface{})["rolledBack"])
+	require.NotContains(t, store.hobbits, "merry")
+	require.NotContains(t, store.hobbits, "pippin")
+
+	resp := httptest.NewRecorder()
+	srv.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "https://cattle.io/v1/schemas/hobbit", nil))
+	schema := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &schema))
+	require.Equal(t, map[string]interface{}{"input": "bulkInput", "output": "bulkOutput"},
+		schema["collectionActions"].(map[string]interface{})["bulk"], "the bulk action is advertised")
+
+	tooMany := make([]string, api.MaxBulkOperations+1)
+	for i := range tooMany {
+		tooMany[i] = `{"op": "delete", "id": "frodo"}`
+	}
+	resp = serve(`{"operations": [` + strings.Join(tooMany, ",") + `]}`)
+	require.Equal(t, http.StatusUnprocessableEntity, resp.Code, resp.Body.String())
+
+	intercepted = nil
+	srv = newHobbitServer(t, types.Schema{
+		ID:                "hobbit",
+		CollectionMethods: []string{http.MethodGet, http.MethodPost},
+		ResourceFields: map[string]types.Field{
+			"name": {Type: "string", Create: true, Required: true},
+		},
+		Store: store,
+	})
+	resp = serve(`{"operations": [{"op": "create", "data": {"name": "pippin"}}]}`)
+	require.NotEqual(t, http.StatusOK, resp.Code, "schemas without Bulk have no bulk action")
+	require.NotContains(t, store.hobbits, "pippin")
+}
+
+// dryRunStore previews creates of its hobbitStore when the request is a dry run
+type dryRunStore struct {
+	*hobbitStore
+}
+
+func (d dryRunStore) SupportsDryRun() bool {
+	return true
+}
+
+func (d dryRunStore) Create(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}) (map[string]interface{}, error) {
+	if apiContext.Option("dryRun") == "true" {
+		data["id"] = data["name"]
+		data["type"] = schema.ID
+		return data, nil
+	}
+	return d.hobbitStore.Create(apiContext, schema, data)
+}
+
+func TestServeDryRun(t *testing.T) {
+	store := &hobbitStore{
+		hobbits: map[string]map[string]interface{}{
+			"frodo": {"id": "frodo", "type": "hobbit", "name": "frodo", "age": int64(50)},
+		},
+	}
+
+	schema := types.Schema{
+		ID:                "hobbit",
+		CollectionMethods: []string{http.MethodGet, http.MethodPost},
+		ResourceMethods:   []string{http.MethodGet, http.MethodPut, http.MethodDelete},
+		ResourceFields: map[string]types.Field{
+			"name": {Type: "string", Create: true, Required: true},
+			"age":  {Type: "int", Create: true, Update: true, Default: 33},
+		},
+		Store: store,
+	}
+	srv := newHobbitServer(t, schema)
+
+	serve := func(srv *api.Server, method, url, body string) (int, map[string]interface{}) {
+		resp := httptest.NewRecorder()
+		srv.ServeHTTP(resp, httptest.NewRequest(method, url, strings.NewReader(body)))
+
+		result := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &result))
+		return resp.Code, result
+	}
+
+	code, _ := serve(srv, http.MethodPost, "https://cattle.io/v1/hobbits?_dryRun=true", `{"name": "sam"}`)
+	require.Equal(t, http.StatusNotImplemented, code, "stores without dry run can't preview creates")
+	require.NotContains(t, store.hobbits, "sam")
+
+	code, _ = serve(srv, http.MethodPut, "https://cattle.io/v1/hobbits/frodo?_dryRun=true", `{"age": 51}`)
+	require.Equal(t, http.StatusNotImplemented, code, "stores without dry run can't preview updates")
+	require.EqualValues(t, 50, store.hobbits["frodo"]["age"])
+
+	code, deleted := serve(srv, http.MethodDelete, "https://cattle.io/v1/hobbits/frodo?_dryRun=true", "")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "frodo", deleted["id"])
+	require.Contains(t, store.hobbits, "frodo")
+
+	schema.Store = dryRunStore{hobbitStore: store}
+	code, created := serve(newHobbitServer(t, schema), http.MethodPost, "https://cattle.io/v1/hobbits?_dryRun=true", `{"name": "sam"}`)
+	require.Equal(t, http.StatusCreated, code)
+	require.Equal(t, "sam", created["name"])
+	require.EqualValues(t, 33, created["age"])
+	require.NotContains(t, store.hobbits, "sam")
+}
+
+func TestServeFields(t *testing.T) {
+	store := &hobbitStore{
+		hobbits: map[string]map[string]interface{}{
+			"frodo": {
+				"id":    "frodo",
+				"type":  "hobbit",
+				"name":  "frodo",
+				"age":   int64(50),
+				"spec":  map[string]interface{}{"ring": "one", "home": "bag end"},
+				"state": "active",
+			},
+		},
+	}
+
+	srv := newHobbitServer(t, types.Schema{
+		ID:                "hobbit",
+		CollectionMethods: []string{http.MethodGet},
+		ResourceMethods:   []string{http.MethodGet},
+		ResourceFields: map[string]types.Field{
+			"name":  {Type: "string"},
+			"age":   {Type: "int"},
+			"state": {Type: "string"},
+			"spec":  {Type: "map[string]"},
+		},
+		Store: store,
+	})
+
+	serve := func(url string) map[string]interface{} {
+		resp := httptest.NewRecorder()
+		srv.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, url, nil))
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+		result := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &result))
+		return result
+	}
+
+	expected := map[string]interface{}{
+		"id":       "frodo",
+		"type":     "hobbit",
+		"baseType": "hobbit",
+		"links":    map[string]interface{}{"self": "https://cattle.io/v1/hobbits/frodo"},
+		"name":     "frodo",
+		"spec":     map[string]interface{}{"ring": "one"},
+	}
+
+	hobbit := serve("https://cattle.io/v1/hobbits/frodo?fields=name,spec.ring,missing")
+	delete(hobbit, "actions")
+	require.Equal(t, expected, hobbit)
+
+	collection := serve("https://cattle.io/v1/hobbits?fields=name&fields=spec.ring")
+	require.Equal(t, []interface{}{"name", "spec.ring"}, collection["fields"])
+	hobbit = collection["data"].([]interface{})[0].(map[string]interface{})
+	delete(hobbit, "actions")
+	require.Equal(t, expected, hobbit)
+}
+
+func TestServeIncludes(t *testing.T) {
+	shires := &countingStore{Store: &hobbitStore{
+		hobbits: map[string]map[string]interface{}{
+			"hobbiton":  {"id": "hobbiton", "type": "shire", "name": "hobbiton"},
+			"buckland":  {"id": "buckland", "type": "shire", "name": "buckland"},
+			"tuckburgh": {"id": "tuckburgh", "type": "shire", "name": "tuckburgh"},
+		},
+	}}
+	hobbits := &countingStore{Store: &hobbitStore{
+		hobbits: map[string]map[string]interface{}{
+			"frodo":  {"id": "frodo", "type": "hobbit", "name": "frodo", "shireId": "hobbiton"},
+			"sam":    {"id": "sam", "type": "hobbit", "name": "sam", "shireId": "hobbiton"},
+			"merry":  {"id": "merry", "type": "hobbit", "name": "merry", "shireId": "buckland"},
+			"pippin": {"id": "pippin", "type": "hobbit", "name": "pippin", "shireId": "tuckburgh"},
+		},
+	}}
+
+	srv := newHobbitServer(t,
+		types.Schema{
+			ID:                "shire",
+			CollectionMethods: []string{http.MethodGet},
+			ResourceMethods:   []string{http.MethodGet},
+			ResourceFields: map[string]types.Field{
+				"name": {Type: "string"},
+			},
+			Store: shires,
+		},
+		types.Schema{
+			ID:                "hobbit",
+			CollectionMethods: []string{http.MethodGet},
+			ResourceMethods:   []string{http.MethodGet},
+			ResourceFields: map[string]types.Field{
+				"name":    {Type: "string"},
+				"shireId": {Type: "reference[shire]"},
+			},
+			Store: hobbits,
+		})
+
+	serve := func(url string) map[string]interface{} {
+		resp := httptest.NewRecorder()
+		srv.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, url, nil))
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+		result := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &result))
+		return result
+	}
+
+	hobbit := serve("https://cattle.io/v1/hobbits/frodo?include=shireId")
+	shire := hobbit["included"].(map[string]interface{})["shireId"].(map[string]interface{})
+	require.Equal(t, "hobbiton", shire["id"])
+	require.Equal(t, "shire", shire["type"])
+	require.Equal(t, "https://cattle.io/v1/shires/hobbiton", shire["links"].(map[string]interface{})["self"])
+
+	shires.calls = 0
+	collection := serve("https://cattle.io/v1/hobbits?include=shireId")
+	for _, item := range collection["data"].([]interface{}) {
+		hobbit := item.(map[string]interface{})
+		included := hobbit["included"].(map[string]interface{})
+		require.Equal(t, hobbit["shireId"], included["shireId"].(map[string]interface{})["id"])
+	}
+	require.Equal(t, 1, shires.calls, "the shires of all hobbits are read at once")
+
+	hobbits.calls = 0
+	collection = serve("https://cattle.io/v1/shires?include=hobbits")
+	counts := map[string]int{}
+	for _, item := range collection["data"].([]interface{}) {
+		shire := item.(map[string]interface{})
+		counts[shire["id"].(string)] = len(shire["included"].(map[string]interface{})["hobbits"].([]interface{}))
+	}
+	require.Equal(t, map[string]int{"hobbiton": 2, "buckland": 1, "tuckburgh": 1}, counts)
+	require.Equal(t, 1, hobbits.calls, "the hobbits of all shires are listed at once")
+
+	shire = serve("https://cattle.io/v1/shires/hobbiton?include=hobbits")
+	var names []string
+	for _, item := range shire["included"].(map[string]interface{})["hobbits"].([]interface{}) {
+		names = append(names, item.(map[string]interface{})["name"].(string))
+	}
+	require.ElementsMatch(t, []string{"frodo", "sam"}, names)
+
+	shire = serve("https://cattle.io/v1/shires/hobbiton?include=missing")
+	require.NotContains(t, shire, "included")
+}
+
+func TestServeSummary(t *testing.T) {
+	store := &hobbitStore{
+		hobbits: map[string]map[string]interface{}{
+			"frodo":  {"id": "frodo", "type": "hobbit", "name": "frodo", "state": "active", "home": "bag end"},
+			"sam":    {"id": "sam", "type": "hobbit", "name": "sam", "state": "active", "home": "bagshot row"},
+			"bilbo":  {"id": "bilbo", "type": "hobbit", "name": "bilbo", "state": "removed", "home": "bag end"},
+			"merry":  {"id": "merry", "type": "hobbit", "name": "merry", "state": "active", "home": "brandy hall"},
+			"pippin": {"id": "pippin", "type": "hobbit", "name": "pippin", "state": "removed", "home": "tuckborough"},
+		},
+	}
+
+	srv := newHobbitServer(t, types.Schema{
+		ID:                "hobbit",
+		CollectionMethods: []string{http.MethodGet},
+		ResourceMethods:   []string{http.MethodGet},
+		ResourceFields: map[string]types.Field{
+			"name":  {Type: "string"},
+			"state": {Type: "string"},
+			"home":  {Type: "string"},
+		},
+		CollectionFilters: map[string]types.Filter{
+			"state": {Modifiers: []types.ModifierType{types.ModifierEQ}},
+			"home":  {Modifiers: []types.ModifierType{types.ModifierEQ}},
+		},
+		Store: store,
+	})
+
+	serve := func(url string) (int, map[string]interface{}) {
+		resp := httptest.NewRecorder()
+		srv.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, url, nil))
+
+		result := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &result))
+		return resp.Code, result
+	}
+
+	code, summary := serve("https://cattle.io/v1/hobbits?summary=state,home&limit=1")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "summary", summary["type"])
+	require.Equal(t, "hobbit", summary["resourceType"])
+	require.Equal(t, float64(5), summary["total"])
+	require.Equal(t, map[string]interface{}{
+		"state": map[string]interface{}{"active": float64(3), "removed": float64(2)},
+		"home": map[string]interface{}{
+			"bag end":     float64(2),
+			"bagshot row": float64(1),
+			"brandy hall": float64(1),
+			"tuckborough": float64(1),
+		},
+	}, summary["counts"])
+
+	code, summary = serve("https://cattle.io/v1/hobbits?summary=home&state=active")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, float64(3), summary["total"])
+	require.Equal(t, map[string]interface{}{
+		"home": map[string]interface{}{"bag end": float64(1), "bagshot row": float64(1), "brandy hall": float64(1)},
+	}, summary["counts"])
+
+	code, summary = serve("https://cattle.io/v1/hobbits?summary")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, float64(5), summary["total"])
+
+	code, _ = serve("https://cattle.io/v1/hobbits?summary=name")
+	require.Equal(t, http.StatusUnprocessableEntity, code)
+}
+
+func TestServeSearch(t *testing.T) {
+	store := &hobbitStore{
+		hobbits: map[string]map[string]interface{}{
+			"frodo": {"id": "frodo", "type": "hobbit", "name": "frodo", "home": "Bag End", "state": "active"},
+			"bilbo": {"id": "bilbo", "type": "hobbit", "name": "bilbo", "home": "Bag End", "state": "removed"},
+			"sam":   {"id": "sam", "type": "hobbit", "name": "sam", "home": "Bagshot Row", "state": "active"},
+			"merry": {"id": "merry", "type": "hobbit", "name": "merry", "home": "Brandy Hall", "state": "active"},
+		},
+	}
+
+	srv := newHobbitServer(t, types.Schema{
+		ID:                "hobbit",
+		CollectionMethods: []string{http.MethodGet},
+		ResourceMethods:   []string{http.MethodGet},
+		ResourceFields: map[string]types.Field{
+			"name":  {Type: "string"},
+			"home":  {Type: "string"},
+			"state": {Type: "string"},
+		},
+		CollectionFilters: map[string]types.Filter{
+			"state": {Modifiers: []types.ModifierType{types.ModifierEQ}},
+		},
+		SearchFields: []string{"name", "home"},
+		Store:        store,
+	})
+
+	serve := func(url string) ([]string, map[string]interface{}) {
+		resp := httptest.NewRecorder()
+		srv.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, url, nil))
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+		result := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &result))
+
+		var ids []string
+		for _, item := range result["data"].([]interface{}) {
+			ids = append(ids, item.(map[string]interface{})["id"].(string))
+		}
+		return ids, result["filters"].(map[string]interface{})
+	}
+
+	ids, filters := serve("https://cattle.io/v1/hobbits")
+	require.Len(t, ids, 4)
+	require.Contains(t, filters, "q")
+	require.Nil(t, filters["q"])
+
+	ids, filters = serve("https://cattle.io/v1/hobbits?q=bag")
+	require.Equal(t, []string{"bilbo", "frodo", "sam"}, ids)
+	require.Equal(t, []interface{}{map[string]interface{}{"modifier": "search", "value": "bag"}}, filters["q"])
+
+	ids, _ = serve("https://cattle.io/v1/hobbits?q=BAG+END&state=active")
+	require.Equal(t, []string{"frodo"}, ids)
+
+	ids, _ = serve("https://cattle.io/v1/hobbits?q=active")
+	require.Empty(t, ids)
+}
+
+func TestServeSortLinks(t *testing.T) {
+	srv := newHobbitServer(t, types.Schema{
+		ID:                "hobbit",
+		CollectionMethods: []string{http.MethodGet},
+		ResourceFields: map[string]types.Field{
+			"name": {Type: "string"},
+			"home": {Type: "string"},
+			"age":  {Type: "int"},
+		},
+		CollectionFilters: map[string]types.Filter{
+			"name": {Modifiers: []types.ModifierType{types.ModifierEQ}},
+			"home": {Modifiers: []types.ModifierType{types.ModifierEQ}},
+			"age":  {Modifiers: []types.ModifierType{types.ModifierEQ}},
+		},
+		Store: &hobbitStore{hobbits: map[string]map[string]interface{}{}},
+	})
+
+	resp := httptest.NewRecorder()
+	srv.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "https://cattle.io/v1/hobbits?sort=home,-age", nil))
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+	result := struct {
+		Sort types.Sort `json:"sort"`
+	}{}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &result))
+	require.Equal(t, map[string]string{
+		"name": "https://cattle.io/v1/hobbits?sort=name%2Chome%2C-age",
+		"home": "https://cattle.io/v1/hobbits?sort=home%2C-age",
+		"age":  "https://cattle.io/v1/hobbits?sort=age%2Chome",
+	}, result.Sort.Links, "sort links keep the other fields of the current sort")
+}
+
+func TestServeFilterExpression(t *testing.T) {
+	store := &hobbitStore{
+		hobbits: map[string]map[string]interface{}{
+			"frodo":  {"id": "frodo", "type": "hobbit", "name": "frodo", "state": "active", "home": "bag end", "age": int64(50)},
+			"fatty":  {"id": "fatty", "type": "hobbit", "name": "fatty", "state": "updating", "home": "crickhollow", "age": int64(33)},
+			"sam":    {"id": "sam", "type": "hobbit", "name": "sam", "state": "updating", "home": "bagshot row", "age": int64(38)},
+			"bilbo":  {"id": "bilbo", "type": "hobbit", "name": "bilbo", "state": "removed", "home": "bag end", "age": int64(111)},
+			"pippin": {"id": "pippin", "type": "hobbit", "name": "pippin", "state": "removed", "home": "great smials", "age": int64(28)},
+		},
+	}
+
+	srv := newHobbitServer(t, types.Schema{
+		ID:                "hobbit",
+		CollectionMethods: []string{http.MethodGet},
+		ResourceMethods:   []string{http.MethodGet},
+		ResourceFields: map[string]types.Field{
+			"name":  {Type: "string"},
+			"state": {Type: "string"},
+			"home":  {Type: "string"},
+			"age":   {Type: "int"},
+		},
+		CollectionFilters: map[string]types.Filter{
+			"name":  {Modifiers: []types.ModifierType{types.ModifierEQ, types.ModifierPrefix}},
+			"state": {Modifiers: []types.ModifierType{types.ModifierEQ, types.ModifierIn}},
+			"home":  {Modifiers: []types.ModifierType{types.ModifierEQ}},
+			"age":   {Modifiers: []types.ModifierType{types.ModifierGT, types.ModifierLT}},
+		},
+		Store: store,
+	})
+
+	serve := func(filter string, query ...string) (int, map[string]interface{}) {
+		values := url.Values{"filter": []string{filter}}
+		for i := 0; i+1 < len(query); i += 2 {
+			values.Add(query[i], query[i+1])
+		}
+
+		resp := httptest.NewRecorder()
+		srv.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "https://cattle.io/v1/hobbits?"+values.Encode(), nil))
+
+		result := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &result))
+		return resp.Code, result
+	}
+
+	ids := func(collection map[string]interface{}) []string {
+		result := []string{}
+		for _, item := range collection["data"].([]interface{}) {
+			result = append(result, item.(map[string]interface{})["id"].(string))
+		}
+		return result
+	}
+
+	code, collection := serve("state=active OR state=updating AND name_prefix=f")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, []string{"fatty", "frodo"}, ids(collection))
+	require.Equal(t, []interface{}{map[string]interface{}{
+		"modifier": "or",
+		"value":    "state=active OR (state=updating AND name_prefix=f)",
+	}}, collection["filters"].(map[string]interface{})["filter"])
+
+	code, collection = serve("(state=active or state=updating) and name_prefix=f")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, []string{"fatty", "frodo"}, ids(collection))
+
+	code, collection = serve(`home="bag end" OR state_in=updating,removed`, "name_prefix", "b")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, []string{"bilbo"}, ids(collection))
+
+	for _, filter := range []string{"state=active OR", "(state=active", "state", `home="bag end`, "state=active )"} {
+		code, collection = serve(filter)
+		require.Equal(t, http.StatusUnprocessableEntity, code, filter)
+		require.Equal(t, "InvalidFormat", collection["code"], filter)
+	}
+
+	code, collection = serve("age_gt=40 AND age_lt=100")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, []string{"frodo"}, ids(collection))
+
+	code, collection = serve("age_gt=old")
+	require.Equal(t, http.StatusUnprocessableEntity, code)
+	require.Equal(t, "InvalidFormat", collection["code"], "values are validated against the field type")
+
+	code, collection = serve("", "age_lt", "young")
+	require.Equal(t, http.StatusUnprocessableEntity, code)
+	require.Equal(t, "InvalidFormat", collection["code"])
+
+	code, collection = serve("state_prefix=a")
+	require.Equal(t, http.StatusUnprocessableEntity, code)
+	require.Equal(t, "InvalidOption", collection["code"])
+}
+
+func TestServeMetrics(t *testing.T) {
+	srv := newHobbitServer(t, types.Schema{
+		ID:                "hobbit",
+		CollectionMethods: []string{http.MethodGet},
+		ResourceMethods:   []string{http.MethodGet},
+		ResourceFields: map[string]types.Field{
+			"name": {Type: "string"},
+		},
+		Store: &hobbitStore{hobbits: map[string]map[string]interface{}{}},
+	})
+
+	ok := metrics.Requests.WithLabelValues("hobbit", http.MethodGet, "200")
+	notAllowed := metrics.Requests.WithLabelValues("hobbit", http.MethodPost, "405")
+	calls := metrics.StoreCalls.WithLabelValues("api", "hobbit", "list")
+	okCount, notAllowedCount, callCount := testutil.ToFloat64(ok), testutil.ToFloat64(notAllowed), testutil.ToFloat64(calls)
+
+	srv.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "https://cattle.io/v1/hobbits", nil))
+	srv.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "https://cattle.io/v1/hobbits", strings.NewReader("{}")))
+
+	require.Equal(t, okCount+1, testutil.ToFloat64(ok))
+	require.Equal(t, notAllowedCount+1, testutil.ToFloat64(notAllowed))
+	require.Equal(t, callCount+1, testutil.ToFloat64(calls))
+}
+
+func TestServeTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	srv := newHobbitServer(t, types.Schema{
+		ID:                "hobbit",
+		CollectionMethods: []string{http.MethodGet},
+		ResourceMethods:   []string{http.MethodGet},
+		ResourceFields: map[string]types.Field{
+			"name": {Type: "string"},
+		},
+		Store: &hobbitStore{hobbits: map[string]map[string]interface{}{}},
+	})
+	srv.TracerProvider = sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	resp := httptest.NewRecorder()
+	srv.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "https://cattle.io/v1/hobbits", nil))
+	require.Equal(t, http.StatusOK, resp.Code)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+
+	list, request := spans[0], spans[1]
+	require.Equal(t, "store.list", list.Name)
+	require.Contains(t, list.Attributes, attribute.String("norman.schema", "hobbit"))
+
+	require.Equal(t, "norman.request", request.Name)
+	require.False(t, request.Parent.IsValid())
+	require.Contains(t, request.Attributes, attribute.String("norman.schema", "hobbit"))
+	require.Contains(t, request.Attributes, attribute.Int("http.response.status_code", http.StatusOK))
+
+	require.Equal(t, request.SpanContext.TraceID(), list.SpanContext.TraceID())
+	require.Equal(t, request.SpanContext.SpanID(), list.Parent.SpanID())
+}
+
+func TestServeAudit(t *testing.T) {
+	store := &hobbitStore{
+		hobbits: map[string]map[string]interface{}{
+			"frodo": {"id": "frodo", "type": "hobbit", "name": "Frodo", "meal": "breakfast", "secret": "ring", types.ResourceFieldResourceVersion: "7"},
+		},
+	}
+
+	sink := &audit.MemorySink{}
+	srv := newHobbitServer(t, types.Schema{
+		ID:                "hobbit",
+		CollectionMethods: []string{http.MethodGet, http.MethodPost},
+		ResourceMethods:   []string{http.MethodGet, http.MethodPut, http.MethodDelete},
+		ResourceFields: map[string]types.Field{
+			"name":   {Type: "string", Create: true},
+			"meal":   {Type: "string", Create: true, Update: true},
+			"secret": {Type: "password", Create: true, Update: true},
+		},
+		Store: store,
+	})
+	srv.AuditSink = sink
+
+	serve := func(method, url, body string) int {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Impersonate-User", "gandalf")
+		req.Header.Add("Impersonate-Group", "wizards")
+		resp := httptest.NewRecorder()
+		srv.ServeHTTP(resp, req)
+		return resp.Code
+	}
+
+	require.Equal(t, http.StatusOK, serve(http.MethodGet, "https://cattle.io/v1/hobbits", ""))
+	require.Empty(t, sink.Events(), "reads are not audited")
+
+	require.Equal(t, http.StatusOK, serve(http.MethodPut, "https://cattle.io/v1/hobbits/frodo",
+		`{"meal": "second breakfast", "secret": "precious"}`))
+	require.Equal(t, http.StatusCreated, serve(http.MethodPost, "https://cattle.io/v1/hobbits",
+		`{"name": "sam", "secret": "potatoes"}`))
+	require.Equal(t, http.StatusNoContent, serve(http.MethodDelete, "https://cattle.io/v1/hobbits/sam", ""))
+
+	events := sink.Events()
+	require.Len(t, events, 3)
+
+	update := events[0]
+	require.Equal(t, "gandalf", update.User)
+	require.Equal(t, []string{"wizards"}, update.Groups)
+	require.Equal(t, http.MethodPut, update.Method)
+	require.Equal(t, "hobbit", update.Schema)
+	require.Equal(t, "frodo", update.ID)
+	require.Equal(t, http.StatusOK, update.Status)
+	require.Equal(t, map[string]interface{}{"meal": "second breakfast", "secret": audit.Redacted}, update.RequestBody)
+	require.Equal(t, audit.Change{Old: "breakfast", New: "second breakfast"}, update.Diff["meal"])
+	require.NotContains(t, update.Diff, "secret", "redacted values don't differ")
+	require.NotContains(t, update.Diff, types.ResourceFieldResourceVersion, "internal keys aren't audited")
+
+	create := events[1]
+	require.Equal(t, http.StatusCreated, create.Status)
+	require.Equal(t, audit.Change{New: "sam"}, create.Diff["name"])
+	require.Equal(t, audit.Change{New: audit.Redacted}, create.Diff["secret"])
+
+	remove := events[2]
+	require.Equal(t, "sam", remove.ID)
+	require.Nil(t, remove.RequestBody)
+	require.Equal(t, audit.Change{Old: "sam"}, remove.Diff["name"])
+
+	large := `{"name": "merry", "meal": "` + strings.Repeat("a", parse.MaxBodySize) + `"}`
+	require.Equal(t, http.StatusUnprocessableEntity, serve(http.MethodPost, "https://cattle.io/v1/hobbits", large))
+	events = sink.Events()
+	require.Len(t, events, 4)
+	require.Nil(t, events[3].RequestBody, "bodies over the size handlers read are left out")
+}
+
+// decoratingWriter adds a field to the resources written in response
+type decoratingWriter struct {
+	api.ResponseWriter
+}
+
+func (d decoratingWriter) Write(apiContext *types.APIContext, code int, obj interface{}) {
+	if data, ok := obj.(map[string]interface{}); ok {
+		decorated := map[string]interface{}{"decorated": true}
+		for k, v := range data {
+			decorated[k] = v
+		}
+		obj = decorated
+	}
+	d.ResponseWriter.Write(apiContext, code, obj)
+}
+
+func TestServeInterceptors(t *testing.T) {
+	store := &hobbitStore{
+		hobbits: map[string]map[string]interface{}{
+			"frodo": {"id": "frodo", "type": "hobbit", "name": "Frodo"},
+		},
+	}
+
+	var calls []string
+	srv := newHobbitServer(t, types.Schema{
+		ID:                "hobbit",
+		CollectionMethods: []string{http.MethodGet},
+		ResourceMethods:   []string{http.MethodGet, http.MethodDelete},
+		ResourceFields: map[string]types.Field{
+			"name":      {Type: "string"},
+			"decorated": {Type: "boolean"},
+		},
+		Store: store,
+	})
+	srv.Interceptors = []api.Interceptor{
+		func(apiRequest *types.APIContext, next api.Next) error {
+			calls = append(calls, "gate "+apiRequest.Method+" "+apiRequest.Type+" "+apiRequest.ID)
+			if apiRequest.Method == http.MethodDelete {
+				return httperror.NewAPIError(httperror.PermissionDenied, "deleting hobbits is disabled")
+			}
+			return next(apiRequest)
+		},
+		func(apiRequest *types.APIContext, next api.Next) error {
+			calls = append(calls, "decorate")
+			apiRequest.ResponseWriter = decoratingWriter{ResponseWriter: apiRequest.ResponseWriter}
+			return next(apiRequest)
+		},
+	}
+
+	resp := httptest.NewRecorder()
+	srv.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "https://cattle.io/v1/hobbits/frodo", nil))
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	require.Equal(t, []string{"gate GET hobbit frodo", "decorate"}, calls)
+
+	hobbit := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &hobbit))
+	require.Equal(t, true, hobbit["decorated"])
+
+	calls = nil
+	resp = httptest.NewRecorder()
+	srv.ServeHTTP(resp, httptest.NewRequest(http.MethodDelete, "https://cattle.io/v1/hobbits/frodo", nil))
+	require.Equal(t, http.StatusForbidden, resp.Code)
+	require.Contains(t, resp.Body.String(), "deleting hobbits is disabled")
+	require.Equal(t, []string{"gate DELETE hobbit frodo"}, calls)
+	require.Contains(t, store.hobbits, "frodo")
+}
+
+func TestServeAsyncAction(t *testing.T) {
+	proceed := make(chan struct{})
+	store := &hobbitStore{
+		hobbits: map[string]map[string]interface{}{
+			"frodo": {"id": "frodo", "type": "hobbit", "name": "Frodo"},
+		},
+	}
+
+	srv := newHobbitServer(t,
+		types.Schema{
+			ID:                "route",
+			CollectionMethods: []string{},
+			ResourceMethods:   []string{},
+			ResourceFields: map[string]types.Field{
+				"destination": {Type: "string"},
+			},
+		},
+		types.Schema{
+			ID:                "journey",
+			CollectionMethods: []string{},
+			ResourceMethods:   []string{},
+			ResourceFields: map[string]types.Field{
+				"destination": {Type: "string"},
+			},
+		},
+		types.Schema{
+			ID:                "hobbit",
+			CollectionMethods: []string{http.MethodGet},
+			ResourceMethods:   []string{http.MethodGet},
+			ResourceFields: map[string]types.Field{
+				"name": {Type: "string"},
+			},
+			ResourceActions: map[string]types.Action{
+				"walk":  {Input: "route", Output: "journey", Async: true},
+				"stray": {Async: true},
+			},
+			ActionHandler: func(actionName string, action *types.Action, apiContext *types.APIContext) error {
+				if actionName == "stray" {
+					return httperror.NewAPIError(httperror.Conflict, "the road goes ever on")
+				}
+				operation.SetProgress(apiContext, 50, "halfway")
+				<-proceed
+				route, err := parse.ReadBody(apiContext.Request)
+				if err != nil {
+					return err
+				}
+				apiContext.WriteResponse(http.StatusOK, map[string]interface{}{
+					"type":        "journey",
+					"destination": route["destination"],
+				})
+				return nil
+			},
+			Store: store,
+		})
+
+	// a real server closes the body of a request once it is answered
+	server := httptest.NewServer(srv)
+	defer server.Close()
+
+	serveAs := func(user, method, url, body string) (int, map[string]interface{}) {
+		req, err := http.NewRequest(method, url, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Impersonate-User", user)
+		resp, err := server.Client().Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		data := map[string]interface{}{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&data))
+		return resp.StatusCode, data
+	}
+	serve := func(method, url, body string) (int, map[string]interface{}) {
+		return serveAs("frodo", method, url, body)
+	}
+	poll := func(url, field string, value interface{}) map[string]interface{} {
+		var data map[string]interface{}
+		require.Eventually(t, func() bool {
+			_, data = serve(http.MethodGet, url, "")
+			return data[field] == value
+		}, 5*time.Second, time.Millisecond)
+		return data
+	}
+
+	code, op := serve(http.MethodPost, server.URL+"/v1/hobbits/frodo?action=walk", `{"destination": "mordor"}`)
+	require.Equal(t, http.StatusAccepted, code)
+	require.Equal(t, "operation", op["type"])
+	require.Equal(t, "walk", op["action"])
+	require.Equal(t, "hobbit", op["resourceType"])
+	require.Equal(t, "frodo", op["resourceId"])
+	require.Equal(t, "running", op["status"])
+
+	self := op["links"].(map[string]interface{})["self"].(string)
+	require.Equal(t, server.URL+"/v1/operations/"+op["id"].(string), self)
+
+	running := poll(self, "message", "halfway")
+	require.Equal(t, "running", running["status"])
+	require.EqualValues(t, 50, running["progress"])
+
+	close(proceed)
+	done := poll(self, "status", "succeeded")
+	require.EqualValues(t, 100, done["progress"])
+	require.Equal(t, "journey", done["resultType"])
+	require.Equal(t, "mordor", done["result"].(map[string]interface{})["destination"], "the input is read after the request was answered")
+	require.NotEmpty(t, done["finished"])
+
+	code, op = serve(http.MethodPost, server.URL+"/v1/hobbits/frodo?action=stray", "")
+	require.Equal(t, http.StatusAccepted, code)
+	failed := poll(op["links"].(map[string]interface{})["self"].(string), "status", "failed")
+	require.Equal(t, "Conflict", failed["error"].(map[string]interface{})["code"])
+
+	code, list := serve(http.MethodGet, server.URL+"/v1/operations", "")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, list["data"], 2)
+
+	code, list = serveAs("sam", http.MethodGet, server.URL+"/v1/operations", "")
+	require.Equal(t, http.StatusOK, code)
+	require.Empty(t, list["data"], "operations are only listed for the user who started them")
+	code, _ = serveAs("sam", http.MethodGet, self, "")
+	require.Equal(t, http.StatusNotFound, code)
+}
+
+func TestOperationSchemaCollision(t *testing.T) {
+	hobbit := types.Schema{
+		ID:              "hobbit",
+		Version:         hobbitVersion,
+		ResourceActions: map[string]types.Action{"walk": {Async: true}},
+	}
+	own := types.Schema{
+		ID:      operation.SchemaID,
+		Version: hobbitVersion,
+	}
+
+	srv := api.NewAPIServer()
+	require.Error(t, srv.AddSchemas(types.NewSchemas().AddSchema(hobbit).AddSchema(own)))
+
+	srv = api.NewAPIServer()
+	require.NoError(t, srv.AddSchemas(types.NewSchemas().AddSchema(hobbit)))
+	require.Error(t, srv.AddSchemas(types.NewSchemas().AddSchema(own)), "operations aren't shadowed by a later schema")
+
+	srv = api.NewAPIServer()
+	require.NoError(t, srv.AddSchemas(types.NewSchemas().AddSchema(own)), "versions without async actions may have their own")
+}
+
+func TestServeAuditWithDecoratingInterceptor(t *testing.T) {
+	store := &hobbitStore{
+		hobbits: map[string]map[string]interface{}{
+			"frodo": {"id": "frodo", "type": "hobbit", "name": "Frodo", "meal": "breakfast"},
+		},
+	}
+
+	sink := &audit.MemorySink{}
+	srv := newHobbitServer(t, types.Schema{
+		ID:                "hobbit",
+		CollectionMethods: []string{http.MethodGet},
+		ResourceMethods:   []string{http.MethodGet, http.MethodPut},
+		ResourceFields: map[string]types.Field{
+			"name": {Type: "string"},
+			"meal": {Type: "string", Update: true},
+		},
+		Store: store,
+	})
+	srv.AuditSink = sink
+	srv.Interceptors = []api.Interceptor{
+		func(apiRequest *types.APIContext, next api.Next) error {
+			apiRequest.ResponseWriter = decoratingWriter{ResponseWriter: apiRequest.ResponseWriter}
+			return next(apiRequest)
+		},
+	}
+
+	resp := httptest.NewRecorder()
+	srv.ServeHTTP(resp, httptest.NewRequest(http.MethodPut, "https://cattle.io/v1/hobbits/frodo",
+		strings.NewReader(`{"meal": "elevenses"}`)))
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+	events := sink.Events()
+	require.Len(t, events, 1, "the event is recorded through a decorated response writer")
+	require.Equal(t, audit.Change{Old: "breakfast", New: "elevenses"}, events[0].Diff["meal"])
+}
@@ -0,0 +1,219 @@
+package writer
+
+import (
+	"github.com/rancher/norman/api/builtin"
+	"github.com/rancher/norman/parse"
+	"github.com/rancher/norman/parse/builder"
+	"github.com/rancher/norman/types"
+	"github.com/rancher/norman/types/convert"
+	"github.com/rancher/norman/types/definition"
+	"github.com/sirupsen/logrus"
+)
+
+// includes holds the resources included in a response. They are loaded for all resources of the response
+// at once, with one list per referenced schema and one per back reference rather than calls per resource.
+type includes struct {
+	// references maps the ID of a referenced schema to its resources by ID
+	references map[string]map[string]map[string]interface{}
+	// backReferences maps a back reference to the resources referencing each ID, back references that
+	// couldn't be listed are missing
+	backReferences map[types.BackReference]map[string][]map[string]interface{}
+}
+
+// loadIncludes loads the resources the requested includes of inputs point to. Resources the user can't
+// access are left out.
+func loadIncludes(context *types.APIContext, inputs []map[string]interface{}) *includes {
+	result := &includes{
+		references:     map[string]map[string]map[string]interface{}{},
+		backReferences: map[types.BackReference]map[string][]map[string]interface{}{},
+	}
+
+	names := parse.Includes(context)
+	if len(names) == 0 {
+		return result
+	}
+
+	refIDs := map[*types.Schema][]string{}
+	backRefIDs := map[types.BackReference][]string{}
+	for _, input := range inputs {
+		schema := context.Schemas.Schema(context.Version, definition.GetFullType(input))
+		if schema == nil || schema.Version.Equals(&builtin.Version) {
+			continue
+		}
+
+		for _, name := range names {
+			if field, ok := schema.ResourceFields[name]; ok && definition.IsReferenceType(field.Type) {
+				refSchema := context.Schemas.Schema(&schema.Version, definition.SubType(field.Type))
+				if refSchema == nil || refSchema.Store == nil || context.AccessControl.CanGet(context, refSchema) != nil {
+					continue
+				}
+				for _, id := range referenceIDs(field, input[name]) {
+					if id != "" {
+						refIDs[refSchema] = append(refIDs[refSchema], id)
+					}
+				}
+				continue
+			}
+
+			id := convert.ToString(input["id"])
+			if id == "" {
+				continue
+			}
+			for _, backRef := range context.Schemas.References(schema) {
+				if backRef.Schema.PluralName == name {
+					if backRef.Schema.Store != nil && context.AccessControl.CanList(context, backRef.Schema) == nil {
+						backRefIDs[backRef] = append(backRefIDs[backRef], id)
+					}
+					break
+				}
+			}
+		}
+	}
+
+	for schema, ids := range refIDs {
+		result.references[schema.ID] = loadReferences(context, schema, ids)
+	}
+	for backRef, ids := range backRefIDs {
+		if resources, ok := loadBackReference(context, backRef, ids); ok {
+			result.backReferences[backRef] = resources
+		}
+	}
+	return result
+}
+
+func referenceIDs(field types.Field, value interface{}) []string {
+	if definition.IsArrayType(field.Type) {
+		return convert.ToStringSlice(value)
+	}
+	return []string{convert.ToString(value)}
+}
+
+// loadReferences returns the resources of schema with ids by ID, listing them at once if the user can list
+// the schema and reading them one by one otherwise
+func loadReferences(context *types.APIContext, schema *types.Schema, ids []string) map[string]map[string]interface{} {
+	result := map[string]map[string]interface{}{}
+	if context.AccessControl.CanList(context, schema) == nil {
+		data, err := schema.Store.List(context, schema, &types.QueryOptions{
+			Conditions: []*types.QueryCondition{
+				types.NewConditionFromString("id", types.ModifierIn, ids...),
+			},
+		})
+		if err != nil {
+			logrus.Debugf("Failed to include %s: %v", schema.PluralName, err)
+			return result
+		}
+		for _, item := range data {
+			if item != nil {
+				result[convert.ToString(item["id"])] = item
+			}
+		}
+		return result
+	}
+
+	for _, id := range ids {
+		if _, ok := result[id]; ok {
+			continue
+		}
+		data, err := schema.Store.ByID(context, schema, id)
+		if err != nil {
+			logrus.Debugf("Failed to include %s %s: %v", schema.ID, id, err)
+			continue
+		}
+		if data != nil {
+			result[id] = data
+		}
+	}
+	return result
+}
+
+// loadBackReference lists the resources of the back reference pointing to ids, grouped by the ID they
+// point to
+func loadBackReference(context *types.APIContext, backRef types.BackReference, ids []string) (map[string][]map[string]interface{}, bool) {
+	schema := backRef.Schema
+	data, err := schema.Store.List(context, schema, &types.QueryOptions{
+		Conditions: []*types.QueryCondition{
+			types.NewConditionFromString(backRef.FieldName, types.ModifierIn, ids...),
+		},
+	})
+	if err != nil {
+		logrus.Debugf("Failed to include %s: %v", schema.PluralName, err)
+		return nil, false
+	}
+
+	result := map[string][]map[string]interface{}{}
+	for _, item := range data {
+		if item != nil {
+			id := convert.ToString(item[backRef.FieldName])
+			result[id] = append(result[id], item)
+		}
+	}
+	return result, true
+}
+
+// included resolves the requested reference fields and back-references of a resource to the resources
+// they point to, keyed by the include name
+func (j *EncodingResponseWriter) included(b *builder.Builder, context *types.APIContext, schema *types.Schema, rawResource *types.RawResource, includes *includes) map[string]interface{} {
+	result := map[string]interface{}{}
+	for _, name := range parse.Includes(context) {
+		if field, ok := schema.ResourceFields[name]; ok && definition.IsReferenceType(field.Type) {
+			if value := j.includeReference(b, context, schema, field, rawResource.Values[name], includes); value != nil {
+				result[name] = value
+			}
+			continue
+		}
+
+		if rawResource.ID == "" {
+			continue
+		}
+
+		for _, backRef := range context.Schemas.References(schema) {
+			if backRef.Schema.PluralName == name {
+				if value := j.includeBackReference(b, context, backRef, rawResource.ID, includes); value != nil {
+					result[name] = value
+				}
+				break
+			}
+		}
+	}
+	return result
+}
+
+func (j *EncodingResponseWriter) includeReference(b *builder.Builder, context *types.APIContext, schema *types.Schema, field types.Field, value interface{}, includes *includes) interface{} {
+	refSchema := context.Schemas.Schema(&schema.Version, definition.SubType(field.Type))
+	if refSchema == nil {
+		return nil
+	}
+	resources := includes.references[refSchema.ID]
+
+	var result []interface{}
+	for _, id := range referenceIDs(field, value) {
+		if data, ok := resources[id]; ok {
+			if resource := j.resource(b, context, refSchema, data, builder.List); resource != nil {
+				result = append(result, resource)
+			}
+		}
+	}
+
+	switch {
+	case len(result) == 0:
+		return nil
+	case !definition.IsArrayType(field.Type):
+		return result[0]
+	}
+	return result
+}
+
+func (j *EncodingResponseWriter) includeBackReference(b *builder.Builder, context *types.APIContext, backRef types.BackReference, id string, includes *includes) []interface{} {
+	resources, ok := includes.backReferences[backRef]
+	if !ok {
+		return nil
+	}
+
+	result := []interface{}{}
+	for _, item := range resources[id] {
+		if resource := j.resource(b, context, backRef.Schema, item, builder.List); resource != nil {
+			result = append(result, resource)
+		}
+	}
+	return result
+}
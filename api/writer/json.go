@@ -4,11 +4,14 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 
+	"github.com/rancher/norman/api/builtin"
 	"github.com/rancher/norman/parse"
 	"github.com/rancher/norman/parse/builder"
 	"github.com/rancher/norman/types"
 	"github.com/rancher/norman/types/definition"
+	"github.com/rancher/norman/types/values"
 	"github.com/sirupsen/logrus"
 )
 
@@ -24,6 +27,18 @@ func (j *EncodingResponseWriter) start(apiContext *types.APIContext, code int, o
 }
 
 func (j *EncodingResponseWriter) Write(apiContext *types.APIContext, code int, obj interface{}) {
+	if data, ok := obj.(map[string]interface{}); ok {
+		if etag := types.ETag(data); etag != "" {
+			apiContext.Response.Header().Set("ETag", etag)
+			if apiContext.Method == http.MethodGet && code == http.StatusOK &&
+				types.ETagMatches(apiContext.Request.Header.Get("If-None-Match"), etag) {
+				_ = AddCommonResponseHeader(apiContext)
+				apiContext.Response.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+	}
+
 	j.start(apiContext, code, obj)
 	_ = j.Body(apiContext, apiContext.Response, obj)
 }
@@ -41,13 +56,21 @@ func (j *EncodingResponseWriter) VersionBody(apiContext *types.APIContext, versi
 
 	switch v := obj.(type) {
 	case []interface{}:
-		output = j.writeInterfaceSlice(builder, apiContext, v)
+		var inputs []map[string]interface{}
+		for _, value := range v {
+			if input, ok := value.(map[string]interface{}); ok {
+				inputs = append(inputs, input)
+			}
+		}
+		output = j.writeInterfaceSlice(builder, apiContext, v, loadIncludes(apiContext, inputs))
 	case []map[string]interface{}:
-		output = j.writeMapSlice(builder, apiContext, v)
+		output = j.writeMapSlice(builder, apiContext, v, loadIncludes(apiContext, v))
 	case map[string]interface{}:
-		output = j.convert(builder, apiContext, v)
+		output = j.convert(builder, apiContext, v, loadIncludes(apiContext, []map[string]interface{}{v}))
 	case types.RawResource:
 		output = v
+	case *types.Summary:
+		output = v
 	}
 
 	if output != nil {
@@ -56,10 +79,10 @@ func (j *EncodingResponseWriter) VersionBody(apiContext *types.APIContext, versi
 
 	return nil
 }
-func (j *EncodingResponseWriter) writeMapSlice(builder *builder.Builder, apiContext *types.APIContext, input []map[string]interface{}) *types.GenericCollection {
+func (j *EncodingResponseWriter) writeMapSlice(builder *builder.Builder, apiContext *types.APIContext, input []map[string]interface{}, includes *includes) *types.GenericCollection {
 	collection := newCollection(apiContext)
 	for _, value := range input {
-		converted := j.convert(builder, apiContext, value)
+		converted := j.convert(builder, apiContext, value, includes)
 		if converted != nil {
 			collection.Data = append(collection.Data, converted)
 		}
@@ -72,12 +95,12 @@ func (j *EncodingResponseWriter) writeMapSlice(builder *builder.Builder, apiCont
 	return collection
 }
 
-func (j *EncodingResponseWriter) writeInterfaceSlice(builder *builder.Builder, apiContext *types.APIContext, input []interface{}) *types.GenericCollection {
+func (j *EncodingResponseWriter) writeInterfaceSlice(builder *builder.Builder, apiContext *types.APIContext, input []interface{}, includes *includes) *types.GenericCollection {
 	collection := newCollection(apiContext)
 	for _, value := range input {
 		switch v := value.(type) {
 		case map[string]interface{}:
-			converted := j.convert(builder, apiContext, v)
+			converted := j.convert(builder, apiContext, v, includes)
 			if converted != nil {
 				collection.Data = append(collection.Data, converted)
 			}
@@ -100,7 +123,7 @@ func toString(val interface{}) string {
 	return fmt.Sprint(val)
 }
 
-func (j *EncodingResponseWriter) convert(b *builder.Builder, context *types.APIContext, input map[string]interface{}) *types.RawResource {
+func (j *EncodingResponseWriter) convert(b *builder.Builder, context *types.APIContext, input map[string]interface{}, includes *includes) *types.RawResource {
 	schema := context.Schemas.Schema(context.Version, definition.GetFullType(input))
 	if schema == nil {
 		return nil
@@ -109,6 +132,28 @@ func (j *EncodingResponseWriter) convert(b *builder.Builder, context *types.APIC
 	if context.Method == http.MethodPost {
 		op = builder.ListForCreate
 	}
+	rawResource := j.resource(b, context, schema, input, op)
+	if rawResource == nil {
+		return nil
+	}
+
+	// builtin types like errors are always complete
+	if schema.Version.Equals(&builtin.Version) {
+		return rawResource
+	}
+
+	included := j.included(b, context, schema, rawResource, includes)
+	if fields := parse.Fields(context); len(fields) > 0 {
+		rawResource.Values = project(rawResource.Values, fields)
+	}
+	if len(included) > 0 {
+		rawResource.Values["included"] = included
+	}
+
+	return rawResource
+}
+
+func (j *EncodingResponseWriter) resource(b *builder.Builder, context *types.APIContext, schema *types.Schema, input map[string]interface{}, op builder.Operation) *types.RawResource {
 	data, err := b.Construct(schema, input, op)
 	if err != nil {
 		logrus.Errorf("Failed to construct object on output: %v", err)
@@ -170,6 +215,18 @@ func (j *EncodingResponseWriter) addLinks(b *builder.Builder, schema *types.Sche
 	}
 }
 
+// project returns the given fields of data, keeping their nesting
+func project(data map[string]interface{}, fields []string) map[string]interface{} {
+	result := map[string]interface{}{}
+	for _, field := range fields {
+		path := strings.Split(field, ".")
+		if value, ok := values.GetValue(data, path...); ok {
+			values.PutValue(result, value, path...)
+		}
+	}
+	return result
+}
+
 func newCollection(apiContext *types.APIContext) *types.GenericCollection {
 	result := &types.GenericCollection{
 		Collection: types.Collection{
@@ -195,6 +252,7 @@ func newCollection(apiContext *types.APIContext) *types.GenericCollection {
 	result.Sort.Reverse = apiContext.URLBuilder.ReverseSort(result.Sort.Order)
 	result.Sort.Links = map[string]string{}
 	result.Pagination = opts.Pagination
+	result.Fields = parse.Fields(apiContext)
 	result.Filters = map[string][]types.Condition{}
 
 	for _, cond := range opts.Conditions {
@@ -208,10 +266,13 @@ func newCollection(apiContext *types.APIContext) *types.GenericCollection {
 		}
 	}
 
+	if _, ok := result.Filters[parse.SearchParam]; !ok && len(apiContext.Schema.SearchFields) > 0 {
+		result.Filters[parse.SearchParam] = nil
+	}
+
 	for queryField := range apiContext.Schema.CollectionFilters {
-		field, ok := apiContext.Schema.ResourceFields[queryField]
-		if ok && (field.Type == "string" || field.Type == "enum") {
-			result.Sort.Links[queryField] = apiContext.URLBuilder.Sort(queryField)
+		if _, ok := apiContext.Schema.ResourceFields[queryField]; ok {
+			result.Sort.Links[queryField] = sortLink(apiContext, result.Sort, queryField)
 		}
 	}
 
@@ -232,3 +293,25 @@ func newCollection(apiContext *types.APIContext) *types.GenericCollection {
 
 	return result
 }
+
+// sortLink links to the collection sorted by field, followed by the other fields of the current sort
+// when the URL builder supports multi-key sorts
+func sortLink(apiContext *types.APIContext, sort *types.Sort, field string) string {
+	builder, ok := apiContext.URLBuilder.(types.MultiSortURLBuilder)
+	if !ok {
+		return apiContext.URLBuilder.Sort(field)
+	}
+
+	fields := []string{field}
+	for _, sortField := range sort.Fields {
+		if sortField.Name == field {
+			continue
+		}
+		if sortField.Order == types.DESC {
+			fields = append(fields, "-"+sortField.Name)
+		} else {
+			fields = append(fields, sortField.Name)
+		}
+	}
+	return builder.SortFields(fields...)
+}
@@ -2,6 +2,7 @@ package clientbase
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/base64"
@@ -256,7 +257,7 @@ func NewAPIClient(opts *ClientOpts) (APIBaseClient, error) {
 	}
 	req.Header.Add("Authorization", opts.getAuthHeader())
 
-	resp, err := client.Do(req)
+	resp, err := do(client, req)
 	if err != nil {
 		return result, err
 	}
@@ -284,7 +285,7 @@ func NewAPIClient(opts *ClientOpts) (APIBaseClient, error) {
 			fmt.Println("GET " + req.URL.String())
 		}
 
-		resp, err = client.Do(req)
+		resp, err = do(client, req)
 		if err != nil {
 			return result, err
 		}
@@ -363,6 +364,18 @@ func (a *APIBaseClient) List(schemaType string, opts *types.ListOpts, respObject
 	return a.Ops.DoList(schemaType, opts, respObject)
 }
 
+func (a *APIBaseClient) Summary(schemaType string, opts *types.ListOpts, groupBy ...string) (*types.Summary, error) {
+	resp := &types.Summary{}
+	err := a.Ops.DoSummary(schemaType, opts, groupBy, resp)
+	return resp, err
+}
+
+// WaitForOperation waits for the operation returned by an asynchronous action to finish, decoding its
+// result into respObject
+func (a *APIBaseClient) WaitForOperation(ctx context.Context, operation *types.Operation, respObject interface{}) error {
+	return a.Ops.DoWaitForOperation(ctx, operation, respObject)
+}
+
 func (a *APIBaseClient) Post(url string, createObj interface{}, respObject interface{}) error {
 	return a.Ops.DoModify("POST", url, createObj, respObject)
 }
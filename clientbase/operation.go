@@ -0,0 +1,64 @@
+package clientbase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rancher/norman/types"
+	"github.com/rancher/norman/types/convert"
+)
+
+// OperationPollInterval is how often DoWaitForOperation reads an operation that is still running
+var OperationPollInterval = time.Second
+
+// DoWaitForOperation polls the operation returned by an asynchronous action until it finished, updating
+// operation as it goes. The result of a succeeded operation is decoded into respObject, if not nil. A failed
+// operation returns its error as an *APIError.
+func (a *APIOperations) DoWaitForOperation(ctx context.Context, operation *types.Operation, respObject interface{}) error {
+	self := operation.Links[SELF]
+	if self == "" {
+		return errors.New("operation has no self link")
+	}
+
+	for operation.Status == "" || operation.Status == "running" {
+		timer := time.NewTimer(OperationPollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		polled := types.Operation{}
+		if err := a.DoGet(self, nil, &polled); err != nil {
+			return err
+		}
+		*operation = polled
+	}
+
+	if operation.Status == "failed" {
+		return operationError(self, operation)
+	}
+	if respObject == nil || operation.Result == nil {
+		return nil
+	}
+	return convert.ToObj(operation.Result, respObject)
+}
+
+func operationError(url string, operation *types.Operation) *APIError {
+	status, _ := convert.ToNumber(operation.Error["status"])
+	code := convert.ToString(operation.Error["code"])
+	message := convert.ToString(operation.Error["message"])
+	body, _ := json.Marshal(operation.Error)
+	return &APIError{
+		URL: url,
+		Msg: fmt.Sprintf("Operation %s of action %s failed. Status [%s]. Message: [%s] from [%s]",
+			operation.ID, operation.Action, code, message, url),
+		StatusCode: int(status),
+		Status:     code,
+		Body:       string(body),
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"net/url"
 	"regexp"
 	"slices"
+	"strings"
 
 	"github.com/gorilla/websocket"
 	"github.com/rancher/norman/types"
@@ -20,13 +21,35 @@ type APIOperations struct {
 	Types  map[string]types.Schema
 	Client *http.Client
 	Dialer *websocket.Dialer
+	// DryRun makes creates, updates and deletes only return what they would do, without persisting it
+	DryRun bool
 }
 
 func (a *APIOperations) SetupRequest(req *http.Request) {
 	req.Header.Add("Authorization", a.Opts.getAuthHeader())
 }
 
+// dryRunURL adds the _dryRun option to the URL of a modifying request if DryRun is set
+func (a *APIOperations) dryRunURL(rawURL string) (string, error) {
+	if !a.DryRun {
+		return rawURL, nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse url %s: %v", rawURL, err)
+	}
+	q := u.Query()
+	q.Set("_dryRun", "true")
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
 func (a *APIOperations) DoDelete(url string) error {
+	url, err := a.dryRunURL(url)
+	if err != nil {
+		return err
+	}
+
 	req, err := http.NewRequest("DELETE", url, nil)
 	if err != nil {
 		return err
@@ -34,7 +57,7 @@ func (a *APIOperations) DoDelete(url string) error {
 
 	a.SetupRequest(req)
 
-	resp, err := a.Client.Do(req)
+	resp, err := do(a.Client, req)
 	if err != nil {
 		return err
 	}
@@ -70,7 +93,7 @@ func (a *APIOperations) DoGet(url string, opts *types.ListOpts, respObject inter
 
 	a.SetupRequest(req)
 
-	resp, err := a.Client.Do(req)
+	resp, err := do(a.Client, req)
 	if err != nil {
 		return err
 	}
@@ -95,6 +118,7 @@ func (a *APIOperations) DoGet(url string, opts *types.ListOpts, respObject inter
 	if err := json.Unmarshal(byteContent, respObject); err != nil {
 		return fmt.Errorf("failed to parse: %s: %w", byteContent, err)
 	}
+	setETag(respObject, resp.Header.Get("ETag"))
 
 	return nil
 }
@@ -117,11 +141,28 @@ func (a *APIOperations) DoList(schemaType string, opts *types.ListOpts, respObje
 	return a.DoGet(collectionURL, opts, respObject)
 }
 
+// DoSummary counts the resources of schemaType matching the filters of opts, grouped by each of groupBy
+func (a *APIOperations) DoSummary(schemaType string, opts *types.ListOpts, groupBy []string, respObject *types.Summary) error {
+	summaryOpts := NewListOpts()
+	if opts != nil {
+		for k, v := range opts.Filters {
+			summaryOpts.Filters[k] = v
+		}
+	}
+	summaryOpts.Filters["summary"] = strings.Join(groupBy, ",")
+
+	return a.DoList(schemaType, summaryOpts, respObject)
+}
+
 func (a *APIOperations) DoNext(nextURL string, respObject interface{}) error {
 	return a.DoGet(nextURL, nil, respObject)
 }
 
 func (a *APIOperations) DoModify(method string, url string, createObj interface{}, respObject interface{}) error {
+	return a.doModify(method, url, http.Header{"Content-Type": []string{"application/json"}}, createObj, respObject)
+}
+
+func (a *APIOperations) doModify(method string, url string, header http.Header, createObj interface{}, respObject interface{}) error {
 	if createObj == nil {
 		createObj = map[string]string{}
 	}
@@ -133,6 +174,11 @@ func (a *APIOperations) DoModify(method string, url string, createObj interface{
 		return err
 	}
 
+	url, err = a.dryRunURL(url)
+	if err != nil {
+		return err
+	}
+
 	if Debug {
 		fmt.Println(method + " " + url)
 		fmt.Println("Request => " + string(bodyContent))
@@ -144,9 +190,11 @@ func (a *APIOperations) DoModify(method string, url string, createObj interface{
 	}
 
 	a.SetupRequest(req)
-	req.Header.Set("Content-Type", "application/json")
+	for key, values := range header {
+		req.Header[key] = values
+	}
 
-	resp, err := a.Client.Do(req)
+	resp, err := do(a.Client, req)
 	if err != nil {
 		return err
 	}
@@ -168,12 +216,32 @@ func (a *APIOperations) DoModify(method string, url string, createObj interface{
 		if Debug {
 			fmt.Println("Response <= " + string(byteContent))
 		}
-		return json.Unmarshal(byteContent, respObject)
+		if err := json.Unmarshal(byteContent, respObject); err != nil {
+			return err
+		}
+		setETag(respObject, resp.Header.Get("ETag"))
 	}
 
 	return nil
 }
 
+// setETag records the ETag of a response on the resource it was decoded into
+func setETag(respObject interface{}, etag string) {
+	if resource, ok := respObject.(interface{ SetETag(string) }); ok && etag != "" {
+		resource.SetETag(etag)
+	}
+}
+
+// modifyHeader returns the headers of a request modifying existing, which must not have changed since
+// it was fetched
+func modifyHeader(contentType string, existing *types.Resource) http.Header {
+	header := http.Header{"Content-Type": []string{contentType}}
+	if existing.ETag != "" {
+		header.Set("If-Match", existing.ETag)
+	}
+	return header
+}
+
 func (a *APIOperations) DoCreate(schemaType string, createObj interface{}, respObject interface{}) error {
 	if createObj == nil {
 		createObj = map[string]string{}
@@ -248,7 +316,43 @@ func (a *APIOperations) doUpdate(schemaType string, replace bool, existing *type
 		return errors.New("Resource type [" + schemaType + "] is not updatable")
 	}
 
-	return a.DoModify("PUT", selfURL, updates, respObject)
+	return a.doModify("PUT", selfURL, modifyHeader("application/json", existing), updates, respObject)
+}
+
+// DoJSONPatch applies a list of RFC 6902 JSON patch operations to the existing resource
+func (a *APIOperations) DoJSONPatch(schemaType string, existing *types.Resource, operations interface{}, respObject interface{}) error {
+	return a.doPatch(schemaType, types.JSONPatchType, existing, operations, respObject)
+}
+
+// DoMergePatch applies an RFC 7386 JSON merge patch to the existing resource
+func (a *APIOperations) DoMergePatch(schemaType string, existing *types.Resource, patch interface{}, respObject interface{}) error {
+	return a.doPatch(schemaType, types.MergePatchType, existing, patch, respObject)
+}
+
+func (a *APIOperations) doPatch(schemaType, patchType string, existing *types.Resource, patch interface{}, respObject interface{}) error {
+	if existing == nil {
+		return errors.New("Existing object is nil")
+	}
+
+	selfURL, ok := existing.Links[SELF]
+	if !ok {
+		return fmt.Errorf("failed to find self URL of [%v]", existing)
+	}
+
+	if respObject == nil {
+		respObject = &map[string]interface{}{}
+	}
+
+	schema, ok := a.Types[schemaType]
+	if !ok {
+		return errors.New("Unknown schema type [" + schemaType + "]")
+	}
+
+	if !slices.Contains(schema.ResourceMethods, "PATCH") {
+		return errors.New("Resource type [" + schemaType + "] is not patchable")
+	}
+
+	return a.doModify("PATCH", selfURL, modifyHeader(patchType, existing), patch, respObject)
 }
 
 func (a *APIOperations) DoByID(schemaType string, id string, respObject interface{}) error {
@@ -355,7 +459,7 @@ func (a *APIOperations) doAction(
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Content-Length", "0")
 
-	resp, err := a.Client.Do(req)
+	resp, err := do(a.Client, req)
 	if err != nil {
 		return err
 	}
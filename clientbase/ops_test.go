@@ -0,0 +1,185 @@
+package clientbase
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/rancher/norman/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateSendsIfMatch(t *testing.T) {
+	var ifMatch []string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		ifMatch = append(ifMatch, req.Header.Get("If-Match"))
+		rw.Header().Set("ETag", `"2"`)
+		_, _ = rw.Write([]byte(`{"id": "frodo", "type": "hobbit"}`))
+	}))
+	defer server.Close()
+
+	ops := &APIOperations{
+		Client: server.Client(),
+		Opts:   &ClientOpts{},
+		Types: map[string]types.Schema{
+			"hobbit": {
+				ResourceMethods: []string{http.MethodGet, http.MethodPut},
+				Links:           map[string]string{COLLECTION: server.URL + "/hobbits"},
+			},
+		},
+	}
+
+	existing := &types.Resource{}
+	assert.NoError(t, ops.DoByID("hobbit", "frodo", existing))
+	assert.Equal(t, `"2"`, existing.ETag)
+	existing.Links = map[string]string{SELF: server.URL + "/hobbits/frodo"}
+
+	updated := &types.Resource{}
+	assert.NoError(t, ops.DoUpdate("hobbit", existing, map[string]interface{}{}, updated))
+	assert.Equal(t, []string{"", `"2"`}, ifMatch)
+	assert.Equal(t, `"2"`, updated.ETag)
+}
+
+func TestSummary(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		query = req.URL.Query()
+		_, _ = rw.Write([]byte(`{"type": "summary", "resourceType": "hobbit", "total": 3, "counts": {"state": {"active": 3}}}`))
+	}))
+	defer server.Close()
+
+	ops := &APIOperations{
+		Client: server.Client(),
+		Opts:   &ClientOpts{},
+		Types: map[string]types.Schema{
+			"hobbit": {
+				CollectionMethods: []string{http.MethodGet},
+				Links:             map[string]string{COLLECTION: server.URL + "/hobbits"},
+			},
+		},
+	}
+
+	opts := NewListOpts()
+	opts.Filters["state"] = "active"
+
+	summary := &types.Summary{}
+	assert.NoError(t, ops.DoSummary("hobbit", opts, []string{"state", "home"}, summary))
+	assert.Equal(t, "state,home", query.Get("summary"))
+	assert.Equal(t, "active", query.Get("state"))
+	assert.NotContains(t, opts.Filters, "summary")
+	assert.Equal(t, int64(3), summary.Total)
+	assert.Equal(t, map[string]map[string]int64{"state": {"active": 3}}, summary.Counts)
+}
+
+func TestRetryAfter(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		bodies = append(bodies, string(body))
+		switch len(bodies) {
+		case 1:
+			rw.Header().Set("Retry-After", "0")
+			rw.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			rw.WriteHeader(http.StatusCreated)
+			_, _ = rw.Write([]byte(`{"id": "frodo", "type": "hobbit"}`))
+		default:
+			rw.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer server.Close()
+
+	ops := &APIOperations{
+		Client: server.Client(),
+		Opts:   &ClientOpts{},
+		Types: map[string]types.Schema{
+			"hobbit": {
+				CollectionMethods: []string{http.MethodPost},
+				Links:             map[string]string{COLLECTION: server.URL + "/hobbits"},
+			},
+		},
+	}
+
+	created := &types.Resource{}
+	assert.NoError(t, ops.DoCreate("hobbit", map[string]interface{}{"name": "frodo"}, created))
+	assert.Equal(t, "frodo", created.ID)
+	assert.Equal(t, []string{`{"name":"frodo"}`, `{"name":"frodo"}`}, bodies, "the body is sent again")
+
+	err := ops.DoCreate("hobbit", map[string]interface{}{"name": "sam"}, created)
+	assert.Equal(t, http.StatusTooManyRequests, err.(*APIError).StatusCode, "no Retry-After, no retry")
+	assert.Len(t, bodies, 3)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	wait, ok := retryAfter("3", now)
+	assert.True(t, ok)
+	assert.Equal(t, 3*time.Second, wait)
+
+	wait, ok = retryAfter(now.Add(10*time.Second).Format(http.TimeFormat), now)
+	assert.True(t, ok)
+	assert.Equal(t, 10*time.Second, wait)
+
+	_, ok = retryAfter("soon", now)
+	assert.False(t, ok)
+}
+
+func TestWaitForOperation(t *testing.T) {
+	defer func(interval time.Duration) { OperationPollInterval = interval }(OperationPollInterval)
+	OperationPollInterval = time.Millisecond
+
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		polls++
+		switch {
+		case req.URL.Path == "/operations/op-failed":
+			_, _ = rw.Write([]byte(`{"id": "op-failed", "type": "operation", "action": "stray", "status": "failed",
+				"error": {"status": 409, "code": "Conflict", "message": "the road goes ever on"}}`))
+		case polls < 3:
+			_, _ = rw.Write([]byte(`{"id": "op-walk", "type": "operation", "status": "running", "progress": 50, "message": "halfway"}`))
+		default:
+			_, _ = rw.Write([]byte(`{"id": "op-walk", "type": "operation", "status": "succeeded", "progress": 100,
+				"result": {"destination": "mordor"}}`))
+		}
+	}))
+	defer server.Close()
+
+	ops := &APIOperations{
+		Client: server.Client(),
+		Opts:   &ClientOpts{},
+	}
+
+	operation := &types.Operation{
+		Resource: types.Resource{ID: "op-walk", Links: map[string]string{SELF: server.URL + "/operations/op-walk"}},
+		Status:   "running",
+	}
+	result := struct {
+		Destination string `json:"destination"`
+	}{}
+	assert.NoError(t, ops.DoWaitForOperation(context.Background(), operation, &result))
+	assert.Equal(t, "mordor", result.Destination)
+	assert.Equal(t, "succeeded", operation.Status)
+	assert.Empty(t, operation.Message)
+	assert.Equal(t, 3, polls)
+
+	operation = &types.Operation{
+		Resource: types.Resource{ID: "op-failed", Links: map[string]string{SELF: server.URL + "/operations/op-failed"}},
+		Status:   "running",
+	}
+	err := ops.DoWaitForOperation(context.Background(), operation, nil)
+	assert.Equal(t, http.StatusConflict, err.(*APIError).StatusCode)
+	assert.Contains(t, err.Error(), "the road goes ever on")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	operation = &types.Operation{
+		Resource: types.Resource{ID: "op-walk", Links: map[string]string{SELF: server.URL + "/operations/op-walk"}},
+		Status:   "running",
+	}
+	assert.ErrorIs(t, ops.DoWaitForOperation(ctx, operation, nil), context.Canceled)
+}
@@ -0,0 +1,73 @@
+package clientbase
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var (
+	// MaxRateLimitRetries is how many times a request answered with 429 Too Many Requests is sent again
+	// after waiting for its Retry-After header
+	MaxRateLimitRetries = 5
+	// MaxRetryAfter caps the wait before retrying a rate limited request, longer waits fail the request
+	MaxRetryAfter = time.Minute
+)
+
+// do sends req, honoring the Retry-After header of 429 Too Many Requests responses
+func do(client *http.Client, req *http.Request) (*http.Response, error) {
+	for retries := 0; ; retries++ {
+		resp, err := client.Do(req)
+		if err != nil || resp.StatusCode != http.StatusTooManyRequests || retries >= MaxRateLimitRetries {
+			return resp, err
+		}
+
+		wait, ok := retryAfter(resp.Header.Get("Retry-After"), time.Now())
+		if !ok || wait > MaxRetryAfter || (req.Body != nil && req.GetBody == nil) {
+			return resp, nil
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+
+		if req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+
+		if Debug {
+			fmt.Println("Rate limited, retrying " + req.Method + " " + req.URL.String() + " after " + wait.String())
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// retryAfter parses a Retry-After header, either a number of seconds or an HTTP date
+func retryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	if wait := date.Sub(now); wait > 0 {
+		return wait, true
+	}
+	return 0, true
+}
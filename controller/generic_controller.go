@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/rancher/lasso/pkg/controller"
+	"github.com/rancher/norman/pkg/metrics"
 	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -54,12 +55,18 @@ func (g *genericController) AddHandler(ctx context.Context, name string, handler
 			return obj, nil
 		}
 		logrus.Tracef("%s calling handler %s %s", g.name, name, key)
+		start := time.Now()
 		result, err := handler(key, obj)
+		metrics.HandlerDuration.WithLabelValues(g.name, name).Observe(time.Since(start).Seconds())
 		runtimeObject, _ := result.(runtime.Object)
 		if _, ok := err.(*ForgetError); ok {
+			metrics.HandlerForgetErrors.WithLabelValues(g.name, name).Inc()
 			logrus.Tracef("%v %v completed with dropped err: %v", g.name, key, err)
 			return runtimeObject, controller.ErrIgnore
 		}
+		if err != nil {
+			metrics.HandlerErrors.WithLabelValues(g.name, name).Inc()
+		}
 		return runtimeObject, err
 	}))
 }
@@ -48,6 +48,7 @@ type {{.schema.CodeName}}Client struct {
 type {{.schema.CodeName}}Operations interface {
     List(opts *types.ListOpts) (*{{.schema.CodeName}}Collection, error)
     ListAll(opts *types.ListOpts) (*{{.schema.CodeName}}Collection, error)
+    Summary(opts *types.ListOpts, groupBy ...string) (*types.Summary, error)
     Create(opts *{{.schema.CodeName}}) (*{{.schema.CodeName}}, error)
     Update(existing *{{.schema.CodeName}}, updates interface{}) (*{{.schema.CodeName}}, error)
     Replace(existing *{{.schema.CodeName}}) (*{{.schema.CodeName}}, error)
@@ -126,6 +127,12 @@ func (c *{{.schema.CodeName}}Client) ListAll(opts *types.ListOpts) (*{{.schema.C
     return resp, err
 }
 
+func (c *{{.schema.CodeName}}Client) Summary(opts *types.ListOpts, groupBy ...string) (*types.Summary, error) {
+    resp := &types.Summary{}
+    err := c.apiClient.Ops.DoSummary({{.schema.CodeName}}Type, opts, groupBy, resp)
+    return resp, err
+}
+
 func (cc *{{.schema.CodeName}}Collection) Next() (*{{.schema.CodeName}}Collection, error) {
     if cc != nil && cc.Pagination != nil && cc.Pagination.Next != "" {
         resp := &{{.schema.CodeName}}Collection{}
@@ -13,6 +13,9 @@ var (
 	MethodNotAllowed = ErrorCode{"MethodNotAllow", 405}
 	Conflict         = ErrorCode{"Conflict", 409}
 
+	UnsupportedMediaType = ErrorCode{"UnsupportedMediaType", 415}
+	TooManyRequests      = ErrorCode{"TooManyRequests", 429}
+
 	InvalidDateFormat  = ErrorCode{"InvalidDateFormat", 422}
 	InvalidFormat      = ErrorCode{"InvalidFormat", 422}
 	InvalidReference   = ErrorCode{"InvalidReference", 422}
@@ -33,6 +36,7 @@ var (
 	InvalidState       = ErrorCode{"InvalidState", 422}
 
 	ServerError        = ErrorCode{"ServerError", 500}
+	NotImplemented     = ErrorCode{"NotImplemented", 501}
 	ClusterUnavailable = ErrorCode{"ClusterUnavailable", 503}
 )
 
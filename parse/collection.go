@@ -8,6 +8,9 @@ import (
 	"github.com/rancher/norman/types"
 )
 
+// SearchParam is the query parameter searching the SearchFields of a schema
+const SearchParam = "q"
+
 var (
 	defaultLimit = int64(1000)
 	maxLimit     = int64(10000)
@@ -24,10 +27,50 @@ func QueryOptions(apiContext *types.APIContext, schema *types.Schema) types.Quer
 	result.Sort = parseSort(schema, apiContext)
 	result.Pagination = parsePagination(apiContext)
 	result.Conditions = parseFilters(schema, apiContext)
+	if search := parseSearch(schema, apiContext); search != nil {
+		result.Conditions = append(result.Conditions, search)
+	}
+	// invalid expressions are rejected by ValidateFilter when parsing the request
+	if filter, err := parseFilterExpression(schema, apiContext); err == nil && filter != nil {
+		result.Conditions = append(result.Conditions, filter)
+	}
 
 	return *result
 }
 
+// Fields parses fields=name,spec.replicas, the fields resources in the response are limited to. Nested
+// fields are separated by dots.
+func Fields(apiContext *types.APIContext) []string {
+	return listParam(apiContext, "fields")
+}
+
+// Includes parses include=clusterId,nodes, the references and back-references whose resources are
+// embedded in the response.
+func Includes(apiContext *types.APIContext) []string {
+	return listParam(apiContext, "include")
+}
+
+// Summary parses summary=state,namespaceId, the filterable fields a summary of the collection counts
+// the resources by. The second return value is false if no summary was requested.
+func Summary(apiContext *types.APIContext) ([]string, bool) {
+	if _, ok := apiContext.Query["summary"]; !ok {
+		return nil, false
+	}
+	return listParam(apiContext, "summary"), true
+}
+
+func listParam(apiContext *types.APIContext, name string) []string {
+	var result []string
+	for _, value := range apiContext.Query[name] {
+		for _, field := range strings.Split(value, ",") {
+			if field = strings.TrimSpace(field); field != "" {
+				result = append(result, field)
+			}
+		}
+	}
+	return result
+}
+
 func parseOrder(apiContext *types.APIContext) types.SortOrder {
 	order := apiContext.Query.Get("order")
 	if types.SortOrder(order) == types.DESC {
@@ -36,15 +79,44 @@ func parseOrder(apiContext *types.APIContext) types.SortOrder {
 	return types.ASC
 }
 
+// parseSort parses sort=field1,-field2 where a leading "-" sorts that field descending. The order
+// parameter reverses the direction of every field.
 func parseSort(schema *types.Schema, apiContext *types.APIContext) types.Sort {
-	sortField := apiContext.Query.Get("sort")
-	if _, ok := schema.CollectionFilters[sortField]; !ok {
-		sortField = ""
+	order := parseOrder(apiContext)
+	result := types.Sort{
+		Order: order,
 	}
-	return types.Sort{
-		Order: parseOrder(apiContext),
-		Name:  sortField,
+
+	for _, name := range strings.Split(apiContext.Query.Get("sort"), ",") {
+		fieldOrder := order
+		name = strings.TrimSpace(name)
+		if strings.HasPrefix(name, "-") {
+			name = name[1:]
+			fieldOrder = reverseOrder(fieldOrder)
+		}
+
+		if _, ok := schema.CollectionFilters[name]; !ok {
+			continue
+		}
+
+		result.Fields = append(result.Fields, types.SortField{
+			Name:  name,
+			Order: fieldOrder,
+		})
+	}
+
+	if len(result.Fields) > 0 {
+		result.Name = result.Fields[0].Name
 	}
+
+	return result
+}
+
+func reverseOrder(order types.SortOrder) types.SortOrder {
+	if order == types.DESC {
+		return types.ASC
+	}
+	return types.DESC
 }
 
 func parsePagination(apiContext *types.APIContext) *types.Pagination {
@@ -90,6 +162,15 @@ func parseNameAndOp(value string) (string, types.ModifierType) {
 	return name, types.ModifierType(op)
 }
 
+// parseSearch parses q=text, matching resources containing text in any of the SearchFields of schema
+func parseSearch(schema *types.Schema, apiContext *types.APIContext) *types.QueryCondition {
+	text := strings.TrimSpace(apiContext.Query.Get(SearchParam))
+	if text == "" || len(schema.SearchFields) == 0 {
+		return nil
+	}
+	return types.NewSearchCondition(SearchParam, text, schema.SearchFields)
+}
+
 func parseFilters(schema *types.Schema, apiContext *types.APIContext) []*types.QueryCondition {
 	var conditions []*types.QueryCondition
 	for key, values := range apiContext.Query {
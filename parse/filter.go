@@ -0,0 +1,277 @@
+package parse
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/types"
+)
+
+// FilterParam is the query parameter holding a filter expression such as
+// state=active OR (state=updating AND name_prefix=x)
+const FilterParam = "filter"
+
+// ValidateFilter returns an InvalidFormat error if the filter expression of a collection request can't be
+// parsed, or if a gt, gte, lt or lte filter has a value that isn't of the type of its field. Schemas
+// without a store don't list resources of their own, their list handler validates the filters against
+// the schemas it lists with ValidateSchemaFilter.
+func ValidateFilter(apiContext *types.APIContext) error {
+	if apiContext.Method != http.MethodGet || apiContext.ID != "" || apiContext.Schema == nil ||
+		apiContext.Schema.Store == nil {
+		return nil
+	}
+	return ValidateSchemaFilter(apiContext, apiContext.Schema)
+}
+
+// ValidateSchemaFilter validates the filters of a collection request like ValidateFilter, against the
+// collection filters of schema
+func ValidateSchemaFilter(apiContext *types.APIContext, schema *types.Schema) error {
+	for key, values := range apiContext.Query {
+		name, mod := parseNameAndOp(key)
+		if filter, ok := schema.CollectionFilters[name]; !ok || !hasModifier(filter, mod) {
+			continue
+		}
+		if err := validateFilterValues(schema, name, mod, values); err != nil {
+			return err
+		}
+	}
+
+	_, err := parseFilterExpression(schema, apiContext)
+	return err
+}
+
+// validateFilterValues checks the values of the ordering modifiers against the type of the field, as values
+// that can't be converted can't be ordered
+func validateFilterValues(schema *types.Schema, name string, mod types.ModifierType, values []string) error {
+	switch mod {
+	case types.ModifierGT, types.ModifierGTE, types.ModifierLT, types.ModifierLTE:
+	default:
+		return nil
+	}
+
+	field := schema.ResourceFields[name]
+	for _, value := range values {
+		if err := types.ValidateCompareValue(field, value); err != nil {
+			return httperror.NewAPIError(httperror.InvalidFormat,
+				fmt.Sprintf("invalid value %q of filter %s_%s, expected a %s", value, name, mod, field.Type))
+		}
+	}
+	return nil
+}
+
+// parseFilterExpression parses the filter expression of the request into a condition tree. Comparisons use
+// the field_modifier=value syntax of the collection filters of schema and are combined with AND and OR,
+// AND binding tighter, and grouped with parentheses. Values containing spaces, parentheses or commas are
+// double quoted, the values of in and notin are separated by commas.
+func parseFilterExpression(schema *types.Schema, apiContext *types.APIContext) (*types.QueryCondition, error) {
+	expression := apiContext.Query.Get(FilterParam)
+	if strings.TrimSpace(expression) == "" {
+		return nil, nil
+	}
+
+	p := &filterParser{
+		schema: schema,
+		input:  expression,
+	}
+
+	condition, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpace()
+	if p.pos < len(p.input) {
+		return nil, p.errorf("unexpected %q", p.input[p.pos:])
+	}
+
+	if condition.Field == "" {
+		condition.Field = FilterParam
+	}
+	return condition, nil
+}
+
+type filterParser struct {
+	schema *types.Schema
+	input  string
+	pos    int
+}
+
+func (p *filterParser) or() (*types.QueryCondition, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.keyword("OR") {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = types.Or(left, right)
+	}
+
+	return left, nil
+}
+
+func (p *filterParser) and() (*types.QueryCondition, error) {
+	left, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.keyword("AND") {
+		right, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		left = types.And(left, right)
+	}
+
+	return left, nil
+}
+
+func (p *filterParser) term() (*types.QueryCondition, error) {
+	p.skipSpace()
+	if p.pos >= len(p.input) {
+		return nil, p.errorf("unexpected end of filter")
+	}
+
+	if p.input[p.pos] != '(' {
+		return p.comparison()
+	}
+
+	p.pos++
+	condition, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpace()
+	if p.pos >= len(p.input) || p.input[p.pos] != ')' {
+		return nil, p.errorf("missing closing parenthesis")
+	}
+	p.pos++
+
+	return condition, nil
+}
+
+func (p *filterParser) comparison() (*types.QueryCondition, error) {
+	start := p.pos
+	for p.pos < len(p.input) && isFilterNameChar(rune(p.input[p.pos])) {
+		p.pos++
+	}
+	if start == p.pos {
+		return nil, p.errorf("expected a field name")
+	}
+
+	name, mod := parseNameAndOp(p.input[start:p.pos])
+	filter, ok := p.schema.CollectionFilters[name]
+	if !ok || !types.ValidMod(mod) || !hasModifier(filter, mod) {
+		return nil, httperror.NewAPIError(httperror.InvalidOption,
+			fmt.Sprintf("filter %s is not supported on %s", p.input[start:p.pos], p.schema.ID))
+	}
+
+	if mod == types.ModifierNull || mod == types.ModifierNotNull {
+		return types.NewConditionFromString(name, mod), nil
+	}
+
+	if p.pos >= len(p.input) || p.input[p.pos] != '=' {
+		return nil, p.errorf("expected = after %s", p.input[start:p.pos])
+	}
+	p.pos++
+
+	list := mod == types.ModifierIn || mod == types.ModifierNotIn
+	var values []string
+	for {
+		value, err := p.value(list)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+
+		if !list || p.pos >= len(p.input) || p.input[p.pos] != ',' {
+			break
+		}
+		p.pos++
+	}
+
+	if err := validateFilterValues(p.schema, name, mod, values); err != nil {
+		return nil, err
+	}
+	return types.NewConditionFromString(name, mod, values...), nil
+}
+
+// value reads a double quoted or bare value, bare values end at spaces, parentheses and in lists commas
+func (p *filterParser) value(list bool) (string, error) {
+	if p.pos < len(p.input) && p.input[p.pos] == '"' {
+		end := p.pos + 1
+		for end < len(p.input) && p.input[end] != '"' {
+			if p.input[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(p.input) {
+			return "", p.errorf("unterminated quoted value")
+		}
+
+		value, err := strconv.Unquote(p.input[p.pos : end+1])
+		if err != nil {
+			return "", p.errorf("invalid quoted value %s", p.input[p.pos:end+1])
+		}
+		p.pos = end + 1
+		return value, nil
+	}
+
+	start := p.pos
+	for p.pos < len(p.input) {
+		c := rune(p.input[p.pos])
+		if unicode.IsSpace(c) || c == '(' || c == ')' || (list && c == ',') {
+			break
+		}
+		p.pos++
+	}
+	return p.input[start:p.pos], nil
+}
+
+// keyword consumes word, ignoring case, if it is the next token of the input
+func (p *filterParser) keyword(word string) bool {
+	p.skipSpace()
+	end := p.pos + len(word)
+	if end > len(p.input) || !strings.EqualFold(p.input[p.pos:end], word) {
+		return false
+	}
+	if end < len(p.input) && !unicode.IsSpace(rune(p.input[end])) && p.input[end] != '(' {
+		return false
+	}
+	p.pos = end
+	return true
+}
+
+func (p *filterParser) skipSpace() {
+	for p.pos < len(p.input) && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *filterParser) errorf(format string, args ...interface{}) error {
+	return httperror.NewAPIError(httperror.InvalidFormat,
+		fmt.Sprintf("invalid filter at position %d: %s", p.pos, fmt.Sprintf(format, args...)))
+}
+
+func isFilterNameChar(c rune) bool {
+	return unicode.IsLetter(c) || unicode.IsDigit(c) || c == '_' || c == '.' || c == '-'
+}
+
+func hasModifier(filter types.Filter, mod types.ModifierType) bool {
+	for _, filterMod := range filter.Modifiers {
+		if filterMod == mod {
+			return true
+		}
+	}
+	return false
+}
@@ -16,6 +16,8 @@ import (
 
 const (
 	maxFormSize = 2 * 1 << 20
+	// MaxBodySize is the size request bodies are read to
+	MaxBodySize = maxFormSize
 )
 
 var (
@@ -142,6 +144,10 @@ func Parse(rw http.ResponseWriter, req *http.Request, schemas *types.Schemas, ur
 		return result, err
 	}
 
+	if err := ValidateFilter(result); err != nil {
+		return result, err
+	}
+
 	return result, nil
 }
 
@@ -229,8 +235,9 @@ func DefaultResolver(typeName string, apiContext *types.APIContext) error {
 	}
 
 	schema := apiContext.Schemas.Schema(apiContext.Version, typeName)
-	if schema == nil && (typeName == builtin.Schema.ID || typeName == builtin.Schema.PluralName) {
-		// Schemas are special, we include it as though part of the API request version
+	if schema == nil && (typeName == builtin.Schema.ID || typeName == builtin.Schema.PluralName ||
+		typeName == builtin.OpenAPI.ID) {
+		// Schemas and the OpenAPI document are special, we include them as though part of the API request version
 		schema = apiContext.Schemas.Schema(&builtin.Version, typeName)
 	}
 	if schema == nil {
@@ -1,12 +1,15 @@
 package parse
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
 
 	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/types"
 	"k8s.io/apimachinery/pkg/util/yaml"
 )
 
@@ -33,6 +36,40 @@ func ReadBody(req *http.Request) (map[string]interface{}, error) {
 	return data, nil
 }
 
+// BufferBody reads the body of req into memory, up to the size the body is read to when parsed, so it can
+// still be read after the server closed it
+func BufferBody(req *http.Request) error {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+
+	content, err := io.ReadAll(io.LimitReader(req.Body, maxFormSize))
+	if err != nil {
+		return httperror.NewAPIError(httperror.InvalidBodyContent,
+			fmt.Sprintf("Failed to read body: %v", err))
+	}
+	req.Body = io.NopCloser(bytes.NewReader(content))
+	return nil
+}
+
+// ReadPatch reads the body of a PATCH request, which must be a JSON patch or a JSON merge patch. The
+// patch is returned along with its content type.
+func ReadPatch(req *http.Request) ([]byte, string, error) {
+	patchType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if patchType != types.JSONPatchType && patchType != types.MergePatchType {
+		return nil, "", httperror.NewAPIError(httperror.UnsupportedMediaType,
+			fmt.Sprintf("PATCH requires content type %s or %s", types.JSONPatchType, types.MergePatchType))
+	}
+
+	patch, err := io.ReadAll(io.LimitReader(req.Body, maxFormSize))
+	if err != nil {
+		return nil, "", httperror.NewAPIError(httperror.InvalidBodyContent,
+			fmt.Sprintf("Failed to read body: %v", err))
+	}
+
+	return patch, patchType, nil
+}
+
 func getDecoder(req *http.Request, reader io.Reader) Decode {
 	if req.Header.Get("Content-type") == "application/yaml" {
 		return yaml.NewYAMLToJSONDecoder(reader).Decode
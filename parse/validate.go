@@ -13,6 +13,7 @@ var (
 		http.MethodPost:   true,
 		http.MethodGet:    true,
 		http.MethodPut:    true,
+		http.MethodPatch:  true,
 		http.MethodDelete: true,
 	}
 )
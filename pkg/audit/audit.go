@@ -0,0 +1,129 @@
+// Package audit records who created, updated, deleted or ran an action on which resource. The API server
+// sends an Event for every mutating request to its Sink, with the values of sensitive fields redacted.
+package audit
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/rancher/norman/types"
+	"github.com/rancher/norman/types/definition"
+)
+
+// Redacted replaces the value of sensitive fields
+const Redacted = "[redacted]"
+
+// Event is the record of a mutating API request
+type Event struct {
+	Time        time.Time         `json:"time"`
+	User        string            `json:"user,omitempty"`
+	Groups      []string          `json:"groups,omitempty"`
+	Method      string            `json:"method"`
+	Schema      string            `json:"schema"`
+	ID          string            `json:"id,omitempty"`
+	Action      string            `json:"action,omitempty"`
+	DryRun      bool              `json:"dryRun,omitempty"`
+	RequestBody interface{}       `json:"requestBody,omitempty"`
+	Status      int               `json:"status"`
+	Diff        map[string]Change `json:"diff,omitempty"`
+}
+
+// Change is the value of a field before and after a request. Old is nil for created fields and New is
+// nil for removed fields.
+type Change struct {
+	Old interface{} `json:"old,omitempty"`
+	New interface{} `json:"new,omitempty"`
+}
+
+// Sink receives the events of an API server
+type Sink interface {
+	Record(event Event) error
+}
+
+// Sensitive returns whether the value of field must not be recorded
+func Sensitive(field types.Field) bool {
+	return field.WriteOnly || field.Type == "password"
+}
+
+// Redact returns a copy of data with the values of the sensitive fields of schema, and of the schemas of its
+// embedded types, replaced by Redacted
+func Redact(schemas *types.Schemas, schema *types.Schema, data map[string]interface{}) map[string]interface{} {
+	if data == nil {
+		return nil
+	}
+
+	result := make(map[string]interface{}, len(data))
+	for name, value := range data {
+		field, ok := schema.ResourceFields[name]
+		switch {
+		case !ok || value == nil:
+			result[name] = value
+		case Sensitive(field):
+			result[name] = Redacted
+		default:
+			result[name] = redactValue(schemas, schema, field.Type, value)
+		}
+	}
+	return result
+}
+
+func redactValue(schemas *types.Schemas, schema *types.Schema, fieldType string, value interface{}) interface{} {
+	switch {
+	case definition.IsArrayType(fieldType):
+		if values, ok := value.([]interface{}); ok {
+			result := make([]interface{}, len(values))
+			for i, v := range values {
+				result[i] = redactValue(schemas, schema, definition.SubType(fieldType), v)
+			}
+			return result
+		}
+	case definition.IsMapType(fieldType):
+		if values, ok := value.(map[string]interface{}); ok {
+			result := make(map[string]interface{}, len(values))
+			for k, v := range values {
+				result[k] = redactValue(schemas, schema, definition.SubType(fieldType), v)
+			}
+			return result
+		}
+	default:
+		values, ok := value.(map[string]interface{})
+		if !ok || schemas == nil {
+			return value
+		}
+		if subSchema := schemas.Schema(&schema.Version, fieldType); subSchema != nil {
+			return Redact(schemas, subSchema, values)
+		}
+	}
+	return value
+}
+
+// Diff returns the top level fields that differ between before and after. Internal keys of the stores,
+// like types.ResourceFieldResourceVersion, aren't part of the API and are left out.
+func Diff(before, after map[string]interface{}) map[string]Change {
+	result := map[string]Change{}
+	for name, old := range before {
+		if internal(name) {
+			continue
+		}
+		if value, ok := after[name]; !ok || !reflect.DeepEqual(old, value) {
+			result[name] = Change{Old: old, New: value}
+		}
+	}
+	for name, value := range after {
+		if internal(name) {
+			continue
+		}
+		if _, ok := before[name]; !ok {
+			result[name] = Change{New: value}
+		}
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+func internal(name string) bool {
+	return strings.HasPrefix(name, ".")
+}
@@ -0,0 +1,102 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rancher/norman/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedact(t *testing.T) {
+	version := types.APIVersion{Version: "v1", Path: "/v1"}
+	schemas := types.NewSchemas().
+		AddSchema(types.Schema{
+			ID:      "key",
+			Version: version,
+			ResourceFields: map[string]types.Field{
+				"name":  {Type: "string"},
+				"value": {Type: "string", WriteOnly: true},
+			},
+		}).
+		AddSchema(types.Schema{
+			ID:      "hobbit",
+			Version: version,
+			ResourceFields: map[string]types.Field{
+				"name":     {Type: "string"},
+				"password": {Type: "password"},
+				"key":      {Type: "key"},
+				"keys":     {Type: "array[key]"},
+				"doors":    {Type: "map[key]"},
+			},
+		})
+
+	data := map[string]interface{}{
+		"name":     "frodo",
+		"password": "mellon",
+		"key":      map[string]interface{}{"name": "bag end", "value": "round"},
+		"keys":     []interface{}{map[string]interface{}{"name": "moria", "value": "mellon"}},
+		"doors":    map[string]interface{}{"west": map[string]interface{}{"name": "durin", "value": "ithildin"}},
+		"unknown":  "kept",
+	}
+
+	assert.Equal(t, map[string]interface{}{
+		"name":     "frodo",
+		"password": Redacted,
+		"key":      map[string]interface{}{"name": "bag end", "value": Redacted},
+		"keys":     []interface{}{map[string]interface{}{"name": "moria", "value": Redacted}},
+		"doors":    map[string]interface{}{"west": map[string]interface{}{"name": "durin", "value": Redacted}},
+		"unknown":  "kept",
+	}, Redact(schemas, schemas.Schema(&version, "hobbit"), data))
+	assert.Equal(t, "mellon", data["password"], "the input is not modified")
+}
+
+func TestDiff(t *testing.T) {
+	assert.Equal(t, map[string]Change{
+		"meal":  {Old: "breakfast", New: "elevenses"},
+		"ring":  {Old: true},
+		"pipes": {New: int64(2)},
+	}, Diff(
+		map[string]interface{}{"name": "frodo", "meal": "breakfast", "ring": true},
+		map[string]interface{}{"name": "frodo", "meal": "elevenses", "pipes": int64(2)},
+	))
+	assert.Nil(t, Diff(map[string]interface{}{"name": "frodo"}, map[string]interface{}{"name": "frodo"}))
+	assert.Nil(t, Diff(
+		map[string]interface{}{"name": "frodo", types.ResourceFieldResourceVersion: "1"},
+		map[string]interface{}{"name": "frodo", types.ResourceFieldResourceVersion: "2"},
+	), "internal keys aren't diffed")
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	events := []Event{
+		{Time: time.Unix(0, 0).UTC(), User: "frodo", Method: "POST", Schema: "hobbit", Status: 201},
+		{Time: time.Unix(1, 0).UTC(), User: "sam", Method: "DELETE", Schema: "hobbit", ID: "frodo", Status: 204},
+	}
+	for _, event := range events {
+		sink, err := NewFileSink(path)
+		require.NoError(t, err)
+		require.NoError(t, sink.Record(event))
+		require.NoError(t, sink.Close())
+	}
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var read []Event
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		read = append(read, event)
+	}
+	require.NoError(t, scanner.Err())
+	assert.Equal(t, events, read, "events are appended one per line")
+}
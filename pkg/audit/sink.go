@@ -0,0 +1,58 @@
+package audit
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+)
+
+// FileSink appends events to a file, one JSON object per line
+type FileSink struct {
+	sync.Mutex
+	file    *os.File
+	encoder *json.Encoder
+}
+
+// NewFileSink opens path for appending, creating it if needed
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &FileSink{
+		file:    file,
+		encoder: json.NewEncoder(file),
+	}, nil
+}
+
+func (f *FileSink) Record(event Event) error {
+	f.Lock()
+	defer f.Unlock()
+	return f.encoder.Encode(event)
+}
+
+func (f *FileSink) Close() error {
+	f.Lock()
+	defer f.Unlock()
+	return f.file.Close()
+}
+
+// MemorySink keeps the events it receives, for tests
+type MemorySink struct {
+	sync.Mutex
+	events []Event
+}
+
+func (m *MemorySink) Record(event Event) error {
+	m.Lock()
+	defer m.Unlock()
+	m.events = append(m.events, event)
+	return nil
+}
+
+// Events returns the events received so far
+func (m *MemorySink) Events() []Event {
+	m.Lock()
+	defer m.Unlock()
+	return append([]Event(nil), m.events...)
+}
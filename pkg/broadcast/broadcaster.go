@@ -3,14 +3,45 @@ package broadcast
 import (
 	"context"
 	"sync"
+
+	"github.com/rancher/norman/pkg/metrics"
 )
 
 type ConnectFunc func() (chan map[string]interface{}, error)
 
+// DefaultBufferSize is the number of events buffered for each subscriber if the Broadcaster doesn't set one
+const DefaultBufferSize = 100
+
+// Policy is what a Broadcaster does with the events of a subscriber whose buffer is full
+type Policy int
+
+const (
+	// Disconnect closes the channel of a subscriber that can't keep up
+	Disconnect Policy = iota
+	// Coalesce replaces an event still buffered for a subscriber with a newer event of the same object, so
+	// only the latest version is delivered. A subscriber is disconnected if its buffer is full of distinct
+	// objects.
+	Coalesce
+)
+
+// Stats counts the events a Broadcaster couldn't deliver as is
+type Stats struct {
+	Subscribers int
+	// Dropped is the number of events lost by disconnecting a subscriber
+	Dropped uint64
+	// Coalesced is the number of buffered events replaced by a newer event of the same object
+	Coalesced uint64
+}
+
 type Broadcaster struct {
 	sync.Mutex
-	running bool
-	subs    map[chan map[string]interface{}]struct{}
+	BufferSize int
+	Policy     Policy
+
+	running   bool
+	subs      map[chan map[string]interface{}]*subscriber
+	dropped   uint64
+	coalesced uint64
 }
 
 func (b *Broadcaster) Subscribe(ctx context.Context, connect ConnectFunc) (chan map[string]interface{}, error) {
@@ -23,26 +54,44 @@ func (b *Broadcaster) Subscribe(ctx context.Context, connect ConnectFunc) (chan
 		}
 	}
 
-	sub := make(chan map[string]interface{}, 100)
+	bufferSize := b.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = DefaultBufferSize
+	}
+
+	sub := newSubscriber(ctx, bufferSize)
 	if b.subs == nil {
-		b.subs = map[chan map[string]interface{}]struct{}{}
+		b.subs = map[chan map[string]interface{}]*subscriber{}
 	}
-	b.subs[sub] = struct{}{}
+	b.subs[sub.c] = sub
+	metrics.BroadcastSubscribers.Inc()
 	go func() {
 		<-ctx.Done()
-		b.unsub(sub, true)
+		b.unsub(sub.c, true)
 	}()
 
-	return sub, nil
+	return sub.c, nil
+}
+
+// Stats returns the number of subscribers and the events dropped and coalesced so far
+func (b *Broadcaster) Stats() Stats {
+	b.Lock()
+	defer b.Unlock()
+	return Stats{
+		Subscribers: len(b.subs),
+		Dropped:     b.dropped,
+		Coalesced:   b.coalesced,
+	}
 }
 
 func (b *Broadcaster) unsub(sub chan map[string]interface{}, lock bool) {
 	if lock {
 		b.Lock()
 	}
-	if _, ok := b.subs[sub]; ok {
-		close(sub)
+	if s, ok := b.subs[sub]; ok {
+		s.close()
 		delete(b.subs, sub)
+		metrics.BroadcastSubscribers.Dec()
 	}
 	if lock {
 		b.Unlock()
@@ -63,13 +112,18 @@ func (b *Broadcaster) start(connect ConnectFunc) error {
 func (b *Broadcaster) stream(input chan map[string]interface{}) {
 	for item := range input {
 		b.Lock()
-		for sub := range b.subs {
-			newItem := cloneMap(item)
-			select {
-			case sub <- newItem:
-			default:
+		for c, sub := range b.subs {
+			switch sub.push(cloneMap(item), b.Policy == Coalesce) {
+			case pushQueued:
+				metrics.BroadcastEvents.WithLabelValues("queued").Inc()
+			case pushCoalesced:
+				b.coalesced++
+				metrics.BroadcastEvents.WithLabelValues("coalesced").Inc()
+			case pushFull:
 				// Slow consumer, drop
-				go b.unsub(sub, true)
+				b.dropped++
+				metrics.BroadcastEvents.WithLabelValues("dropped").Inc()
+				b.unsub(c, false)
 			}
 		}
 		b.Unlock()
@@ -83,6 +137,132 @@ func (b *Broadcaster) stream(input chan map[string]interface{}) {
 	b.Unlock()
 }
 
+type pushResult int
+
+const (
+	pushQueued pushResult = iota
+	pushCoalesced
+	pushFull
+)
+
+// subscriber buffers up to size events for a consumer, c is fed from the buffer by run
+type subscriber struct {
+	sync.Mutex
+	c       chan map[string]interface{}
+	size    int
+	queue   []*queuedEvent
+	pending map[string]*queuedEvent
+	signal  chan struct{}
+	done    chan struct{}
+}
+
+type queuedEvent struct {
+	key  string
+	data map[string]interface{}
+}
+
+func newSubscriber(ctx context.Context, size int) *subscriber {
+	s := &subscriber{
+		c:       make(chan map[string]interface{}),
+		size:    size,
+		pending: map[string]*queuedEvent{},
+		signal:  make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	go s.run(ctx)
+	return s
+}
+
+func (s *subscriber) push(data map[string]interface{}, coalesce bool) pushResult {
+	s.Lock()
+	defer s.Unlock()
+
+	key := ""
+	if coalesce {
+		key = objectKey(data)
+	}
+	if event, ok := s.pending[key]; ok && key != "" {
+		event.data = data
+		return pushCoalesced
+	}
+
+	if len(s.queue) >= s.size {
+		return pushFull
+	}
+
+	event := &queuedEvent{
+		key:  key,
+		data: data,
+	}
+	s.queue = append(s.queue, event)
+	if key != "" {
+		s.pending[key] = event
+	}
+
+	select {
+	case s.signal <- struct{}{}:
+	default:
+	}
+	return pushQueued
+}
+
+func (s *subscriber) pop() (map[string]interface{}, bool) {
+	s.Lock()
+	defer s.Unlock()
+
+	if len(s.queue) == 0 {
+		return nil, false
+	}
+
+	event := s.queue[0]
+	s.queue = s.queue[1:]
+	if event.key != "" {
+		delete(s.pending, event.key)
+	}
+	return event.data, true
+}
+
+// run feeds c from the buffer. Events still buffered once the subscriber is closed are delivered before
+// closing c, unless the context of the consumer is done.
+func (s *subscriber) run(ctx context.Context) {
+	defer close(s.c)
+	for {
+		data, ok := s.pop()
+		if !ok {
+			select {
+			case <-s.signal:
+				continue
+			case <-s.done:
+				if data, ok = s.pop(); !ok {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		select {
+		case s.c <- data:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *subscriber) close() {
+	close(s.done)
+}
+
+// objectKey identifies the object of an event, events without an id such as bookmarks are never coalesced
+func objectKey(data map[string]interface{}) string {
+	id, _ := data["id"].(string)
+	if id == "" {
+		return ""
+	}
+	typeName, _ := data["type"].(string)
+	return typeName + "/" + id
+}
+
 func cloneMap(data map[string]interface{}) map[string]interface{} {
 	if data == nil {
 		return nil
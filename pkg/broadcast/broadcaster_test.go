@@ -0,0 +1,110 @@
+package broadcast
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func event(id string, version int) map[string]interface{} {
+	return map[string]interface{}{
+		"id":      id,
+		"type":    "hobbit",
+		"version": version,
+	}
+}
+
+func receive(t *testing.T, c chan map[string]interface{}) (map[string]interface{}, bool) {
+	select {
+	case item, ok := <-c:
+		return item, ok
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for event")
+		return nil, false
+	}
+}
+
+// waitFor sends until the fast subscriber received every event, so the broadcaster has pushed them all
+func waitFor(t *testing.T, input chan map[string]interface{}, fast chan map[string]interface{}, items ...map[string]interface{}) {
+	for _, item := range items {
+		input <- item
+		received, ok := receive(t, fast)
+		require.True(t, ok)
+		require.Equal(t, item, received)
+	}
+}
+
+// waitForPump waits until the pump of the subscriber of c took the buffered event off its queue
+func waitForPump(t *testing.T, b *Broadcaster, c chan map[string]interface{}) {
+	b.Lock()
+	sub := b.subs[c]
+	b.Unlock()
+
+	assert.Eventually(t, func() bool {
+		sub.Lock()
+		defer sub.Unlock()
+		return len(sub.queue) == 0
+	}, 5*time.Second, time.Millisecond)
+}
+
+func TestDisconnectSlowConsumer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	input := make(chan map[string]interface{})
+	b := &Broadcaster{BufferSize: 2}
+	connect := func() (chan map[string]interface{}, error) { return input, nil }
+
+	slow, err := b.Subscribe(ctx, connect)
+	require.NoError(t, err)
+	fast, err := b.Subscribe(ctx, connect)
+	require.NoError(t, err)
+
+	// the first event is taken off the buffer by the pump of the slow subscriber, two more fill it
+	waitFor(t, input, fast, event("frodo", 1))
+	waitForPump(t, b, slow)
+	waitFor(t, input, fast, event("sam", 1), event("merry", 1), event("pippin", 1))
+
+	assert.Equal(t, Stats{Subscribers: 1, Dropped: 1}, b.Stats())
+
+	var ids []string
+	for item := range slow {
+		ids = append(ids, item["id"].(string))
+	}
+	assert.Equal(t, []string{"frodo", "sam", "merry"}, ids)
+
+	close(input)
+	_, ok := receive(t, fast)
+	assert.False(t, ok)
+}
+
+func TestCoalesceSlowConsumer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	input := make(chan map[string]interface{})
+	b := &Broadcaster{BufferSize: 2, Policy: Coalesce}
+	connect := func() (chan map[string]interface{}, error) { return input, nil }
+
+	slow, err := b.Subscribe(ctx, connect)
+	require.NoError(t, err)
+	fast, err := b.Subscribe(ctx, connect)
+	require.NoError(t, err)
+
+	waitFor(t, input, fast, event("frodo", 1))
+	waitForPump(t, b, slow)
+	waitFor(t, input, fast, event("sam", 1), event("merry", 1), event("sam", 2), event("merry", 3))
+
+	assert.Equal(t, Stats{Subscribers: 2, Coalesced: 2}, b.Stats())
+
+	close(input)
+
+	var received []map[string]interface{}
+	for item := range slow {
+		received = append(received, item)
+	}
+	assert.Equal(t, []map[string]interface{}{event("frodo", 1), event("sam", 2), event("merry", 3)}, received)
+}
@@ -0,0 +1,123 @@
+// Package metrics holds the Prometheus collectors of the API server, stores, broadcasters and controllers.
+// The collectors always count, but are only exposed once registered with a registry of the caller's
+// choosing through Register.
+package metrics
+
+import (
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "norman"
+
+var (
+	Requests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "api",
+		Name:      "requests_total",
+		Help:      "Number of API requests by schema, method and status code",
+	}, []string{"schema", "method", "code"})
+
+	RequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "api",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of API requests by schema and method",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"schema", "method"})
+
+	StoreCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "store",
+		Name:      "calls_total",
+		Help:      "Number of store calls by store layer, schema and operation",
+	}, []string{"layer", "schema", "operation"})
+
+	StoreErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "store",
+		Name:      "errors_total",
+		Help:      "Number of failed store calls by store layer, schema and operation",
+	}, []string{"layer", "schema", "operation"})
+
+	BroadcastSubscribers = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "broadcast",
+		Name:      "subscribers",
+		Help:      "Number of subscribers of shared watches",
+	})
+
+	BroadcastEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "broadcast",
+		Name:      "events_total",
+		Help:      "Number of events broadcast to subscribers by result, one of queued, coalesced or dropped",
+	}, []string{"result"})
+
+	HandlerDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "controller",
+		Name:      "handler_duration_seconds",
+		Help:      "Duration of controller handler calls by controller and handler name",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"controller", "handler"})
+
+	HandlerErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "controller",
+		Name:      "handler_errors_total",
+		Help:      "Number of controller handler calls returning an error by controller and handler name",
+	}, []string{"controller", "handler"})
+
+	HandlerForgetErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "controller",
+		Name:      "handler_forget_errors_total",
+		Help:      "Number of controller handler calls returning a ForgetError by controller and handler name",
+	}, []string{"controller", "handler"})
+)
+
+// Collectors returns every collector of norman
+func Collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		Requests,
+		RequestDuration,
+		StoreCalls,
+		StoreErrors,
+		BroadcastSubscribers,
+		BroadcastEvents,
+		HandlerDuration,
+		HandlerErrors,
+		HandlerForgetErrors,
+	}
+}
+
+// Register exposes the collectors of norman through registerer. Collectors already registered with it
+// are skipped, so registering twice is harmless.
+func Register(registerer prometheus.Registerer) error {
+	for _, collector := range Collectors() {
+		if err := registerer.Register(collector); err != nil {
+			var alreadyRegistered prometheus.AlreadyRegisteredError
+			if !errors.As(err, &alreadyRegistered) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ObserveRequest records an API request of schema served with code
+func ObserveRequest(schema, method, code string, start time.Time) {
+	Requests.WithLabelValues(schema, method, code).Inc()
+	RequestDuration.WithLabelValues(schema, method).Observe(time.Since(start).Seconds())
+}
+
+// ObserveStoreCall records a call of operation on the store layer for schema
+func ObserveStoreCall(layer, schema, operation string, err error) {
+	StoreCalls.WithLabelValues(layer, schema, operation).Inc()
+	if err != nil {
+		StoreErrors.WithLabelValues(layer, schema, operation).Inc()
+	}
+}
@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rancher/norman/store/empty"
+	"github.com/rancher/norman/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingStore struct {
+	empty.Store
+}
+
+func (f *failingStore) Delete(apiContext *types.APIContext, schema *types.Schema, id string) (map[string]interface{}, error) {
+	return nil, errors.New("failed")
+}
+
+func TestRegister(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	require.NoError(t, Register(registry))
+	require.NoError(t, Register(registry))
+
+	StoreCalls.WithLabelValues("test", "hobbit", "list").Inc()
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	var names []string
+	for _, family := range families {
+		names = append(names, family.GetName())
+	}
+	assert.Contains(t, names, "norman_store_calls_total")
+}
+
+func TestInstrumentStore(t *testing.T) {
+	schema := &types.Schema{ID: "hobbit"}
+	store := InstrumentStore("test", &failingStore{})
+
+	calls := testutil.ToFloat64(StoreCalls.WithLabelValues("test", "hobbit", "delete"))
+	errs := testutil.ToFloat64(StoreErrors.WithLabelValues("test", "hobbit", "delete"))
+	listErrs := testutil.ToFloat64(StoreErrors.WithLabelValues("test", "hobbit", "list"))
+
+	_, err := store.Delete(nil, schema, "frodo")
+	assert.Error(t, err)
+	_, err = store.List(nil, schema, nil)
+	assert.NoError(t, err)
+
+	assert.Equal(t, calls+1, testutil.ToFloat64(StoreCalls.WithLabelValues("test", "hobbit", "delete")))
+	assert.Equal(t, errs+1, testutil.ToFloat64(StoreErrors.WithLabelValues("test", "hobbit", "delete")))
+	assert.Equal(t, listErrs, testutil.ToFloat64(StoreErrors.WithLabelValues("test", "hobbit", "list")))
+}
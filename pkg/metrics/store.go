@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"github.com/rancher/norman/types"
+)
+
+// InstrumentStore counts the calls and errors of store under the layer name, so the layers of a store
+// chain can be told apart
+func InstrumentStore(layer string, store types.Store) types.Store {
+	return &instrumentedStore{
+		layer: layer,
+		store: store,
+	}
+}
+
+type instrumentedStore struct {
+	layer string
+	store types.Store
+}
+
+func (s *instrumentedStore) Context() types.StorageContext {
+	return s.store.Context()
+}
+
+func (s *instrumentedStore) SupportsDryRun() bool {
+	return types.SupportsDryRun(s.store)
+}
+
+func (s *instrumentedStore) ByID(apiContext *types.APIContext, schema *types.Schema, id string) (map[string]interface{}, error) {
+	data, err := s.store.ByID(apiContext, schema, id)
+	ObserveStoreCall(s.layer, schema.ID, "byID", err)
+	return data, err
+}
+
+func (s *instrumentedStore) List(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions) ([]map[string]interface{}, error) {
+	data, err := s.store.List(apiContext, schema, opt)
+	ObserveStoreCall(s.layer, schema.ID, "list", err)
+	return data, err
+}
+
+func (s *instrumentedStore) Create(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}) (map[string]interface{}, error) {
+	data, err := s.store.Create(apiContext, schema, data)
+	ObserveStoreCall(s.layer, schema.ID, "create", err)
+	return data, err
+}
+
+func (s *instrumentedStore) Update(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}, id string) (map[string]interface{}, error) {
+	data, err := s.store.Update(apiContext, schema, data, id)
+	ObserveStoreCall(s.layer, schema.ID, "update", err)
+	return data, err
+}
+
+func (s *instrumentedStore) Delete(apiContext *types.APIContext, schema *types.Schema, id string) (map[string]interface{}, error) {
+	data, err := s.store.Delete(apiContext, schema, id)
+	ObserveStoreCall(s.layer, schema.ID, "delete", err)
+	return data, err
+}
+
+func (s *instrumentedStore) Watch(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions) (chan map[string]interface{}, error) {
+	c, err := s.store.Watch(apiContext, schema, opt)
+	ObserveStoreCall(s.layer, schema.ID, "watch", err)
+	return c, err
+}
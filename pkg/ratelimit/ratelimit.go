@@ -0,0 +1,161 @@
+// Package ratelimit limits the rate of API requests with token buckets kept per user, schema and method.
+// A Limiter answers requests over their limit with 429 Too Many Requests and a Retry-After header. It
+// isn't part of an api.Server by default, callers register it with its interceptors:
+//
+//	srv.Interceptors = append(srv.Interceptors, limiter.Intercept)
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rancher/norman/api"
+	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/types"
+)
+
+const (
+	userHeader = "Impersonate-User"
+
+	pruneInterval = time.Minute
+)
+
+// Limit is the sustained rate of requests per second and the burst of requests allowed on top of it. The
+// zero Limit doesn't limit.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+func (l Limit) burst() float64 {
+	if l.Burst < 1 {
+		return 1
+	}
+	return float64(l.Burst)
+}
+
+// Limiter limits the requests of each user to each method of a schema, its zero value doesn't limit
+type Limiter struct {
+	// Default is the limit of schemas without one in Limits
+	Default Limit
+	// Limits overrides Default by schema ID, as in "hobbit", or by schema ID and method, as in "hobbit/GET"
+	Limits map[string]Limit
+	// User returns who requests are counted for, ImpersonatedUser if nil. It must return an identity the
+	// client can't choose, the impersonation header is set by the proxy authenticating requests in front
+	// of the server. Servers reached without such a proxy use RemoteHost.
+	User func(apiRequest *types.APIContext) string
+
+	lock      sync.Mutex
+	buckets   map[string]*bucket
+	lastPrune time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	// full is when the bucket is refilled, after which it can be forgotten
+	full time.Time
+}
+
+// take removes a token from the bucket if there is one, otherwise it returns how long until there is
+func (b *bucket) take(limit Limit, now time.Time) time.Duration {
+	b.tokens = math.Min(limit.burst(), b.tokens+now.Sub(b.last).Seconds()*limit.Rate)
+	b.last = now
+	if b.tokens < 1 {
+		return rateDuration(1-b.tokens, limit)
+	}
+	b.tokens--
+	b.full = now.Add(rateDuration(limit.burst()-b.tokens, limit))
+	return 0
+}
+
+// rateDuration returns how long it takes to add tokens to a bucket
+func rateDuration(tokens float64, limit Limit) time.Duration {
+	return time.Duration(tokens / limit.Rate * float64(time.Second))
+}
+
+// Intercept is the api.Interceptor rejecting the requests over their limit
+func (l *Limiter) Intercept(apiRequest *types.APIContext, next api.Next) error {
+	limit := l.limit(apiRequest.Type, apiRequest.Method)
+	if limit.Rate <= 0 {
+		return next(apiRequest)
+	}
+
+	wait := l.take(l.user(apiRequest)+"\x00"+apiRequest.Type+"\x00"+apiRequest.Method, limit, time.Now())
+	if wait <= 0 {
+		return next(apiRequest)
+	}
+
+	retryAfter := int(math.Ceil(wait.Seconds()))
+	apiRequest.Response.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	return httperror.NewAPIError(httperror.TooManyRequests,
+		fmt.Sprintf("Rate limit exceeded, retry after %d seconds", retryAfter))
+}
+
+func (l *Limiter) limit(schemaID, method string) Limit {
+	if limit, ok := l.Limits[schemaID+"/"+method]; ok {
+		return limit
+	}
+	if limit, ok := l.Limits[schemaID]; ok {
+		return limit
+	}
+	return l.Default
+}
+
+func (l *Limiter) user(apiRequest *types.APIContext) string {
+	if l.User != nil {
+		return l.User(apiRequest)
+	}
+	return ImpersonatedUser(apiRequest)
+}
+
+// ImpersonatedUser counts requests for the authenticated user, set in the impersonation header by the
+// proxy in front of the server, or for the remote host of anonymous requests
+func ImpersonatedUser(apiRequest *types.APIContext) string {
+	if user := apiRequest.Request.Header.Get(userHeader); user != "" {
+		return user
+	}
+	return RemoteHost(apiRequest)
+}
+
+// RemoteHost counts requests for the host they come from
+func RemoteHost(apiRequest *types.APIContext) string {
+	host, _, err := net.SplitHostPort(apiRequest.Request.RemoteAddr)
+	if err != nil {
+		return apiRequest.Request.RemoteAddr
+	}
+	return host
+}
+
+func (l *Limiter) take(key string, limit Limit, now time.Time) time.Duration {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.buckets == nil {
+		l.buckets = map[string]*bucket{}
+	}
+	if now.Sub(l.lastPrune) > pruneInterval {
+		l.prune(now)
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: limit.burst(), last: now}
+		l.buckets[key] = b
+	}
+	return b.take(limit, now)
+}
+
+// prune forgets the buckets that are full again, a new bucket would be the same
+func (l *Limiter) prune(now time.Time) {
+	for key, b := range l.buckets {
+		if now.After(b.full) {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastPrune = now
+}
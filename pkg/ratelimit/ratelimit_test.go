@@ -0,0 +1,107 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rancher/norman/api"
+	"github.com/rancher/norman/store/empty"
+	"github.com/rancher/norman/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTake(t *testing.T) {
+	limiter := &Limiter{}
+	limit := Limit{Rate: 2, Burst: 3}
+	now := time.Unix(0, 0)
+
+	for i := 0; i < 3; i++ {
+		assert.Zero(t, limiter.take("frodo", limit, now), "the burst is allowed")
+	}
+	assert.Equal(t, 500*time.Millisecond, limiter.take("frodo", limit, now))
+	assert.Zero(t, limiter.take("sam", limit, now), "buckets are kept per key")
+
+	now = now.Add(250 * time.Millisecond)
+	assert.Equal(t, 250*time.Millisecond, limiter.take("frodo", limit, now), "rejected requests don't take tokens")
+
+	now = now.Add(250 * time.Millisecond)
+	assert.Zero(t, limiter.take("frodo", limit, now))
+	assert.Equal(t, 500*time.Millisecond, limiter.take("frodo", limit, now))
+
+	now = now.Add(2 * time.Minute)
+	limiter.take("merry", limit, now)
+	assert.Len(t, limiter.buckets, 1, "full buckets are pruned")
+}
+
+func TestLimit(t *testing.T) {
+	limiter := &Limiter{
+		Default: Limit{Rate: 1},
+		Limits: map[string]Limit{
+			"hobbit":      {Rate: 10},
+			"hobbit/POST": {Rate: 100},
+			"dwarf":       {},
+		},
+	}
+
+	assert.Equal(t, Limit{Rate: 100}, limiter.limit("hobbit", http.MethodPost))
+	assert.Equal(t, Limit{Rate: 10}, limiter.limit("hobbit", http.MethodGet))
+	assert.Equal(t, Limit{}, limiter.limit("dwarf", http.MethodGet))
+	assert.Equal(t, Limit{Rate: 1}, limiter.limit("elf", http.MethodGet))
+}
+
+// newServer returns a server listing hobbits through limiter, and a func listing them as a user
+func newServer(t *testing.T, limiter *Limiter) func(user string) *httptest.ResponseRecorder {
+	version := types.APIVersion{
+		Group:   "shire.cattle.io",
+		Version: "v1",
+		Path:    "/v1",
+	}
+
+	schemas := types.NewSchemas().
+		AddSchema(types.Schema{
+			ID:                "hobbit",
+			Version:           version,
+			CollectionMethods: []string{http.MethodGet},
+			Store:             &empty.Store{},
+		})
+
+	srv := api.NewAPIServer()
+	srv.Interceptors = append(srv.Interceptors, limiter.Intercept)
+	require.NoError(t, srv.AddSchemas(schemas))
+
+	return func(user string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "https://cattle.io/v1/hobbits", nil)
+		req.Header.Set("Impersonate-User", user)
+		resp := httptest.NewRecorder()
+		srv.ServeHTTP(resp, req)
+		return resp
+	}
+}
+
+func TestIntercept(t *testing.T) {
+	list := newServer(t, &Limiter{Limits: map[string]Limit{"hobbit": {Rate: 0.1}}})
+
+	require.Equal(t, http.StatusOK, list("frodo").Code)
+
+	resp := list("frodo")
+	require.Equal(t, http.StatusTooManyRequests, resp.Code)
+	require.Equal(t, "10", resp.Header().Get("Retry-After"))
+	require.Contains(t, resp.Body.String(), `"code":"TooManyRequests"`)
+
+	require.Equal(t, http.StatusOK, list("sam").Code, "authenticated users are limited separately")
+	require.Equal(t, http.StatusOK, list("").Code)
+	require.Equal(t, http.StatusTooManyRequests, list("").Code, "anonymous requests are limited by host")
+}
+
+func TestInterceptRemoteHost(t *testing.T) {
+	list := newServer(t, &Limiter{
+		Limits: map[string]Limit{"hobbit": {Rate: 0.1}},
+		User:   RemoteHost,
+	})
+
+	require.Equal(t, http.StatusOK, list("frodo").Code)
+	require.Equal(t, http.StatusTooManyRequests, list("sam").Code, "the impersonation header isn't trusted")
+}
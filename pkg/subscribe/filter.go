@@ -0,0 +1,82 @@
+package subscribe
+
+import (
+	"github.com/rancher/norman/types"
+	"github.com/rancher/norman/types/convert"
+	"github.com/rancher/norman/types/slice"
+)
+
+// watchFilter applies the collection filters and namespaces of a subscription to the watch events of one
+// schema. The namespaces are those the store left in the options of the watch. An object the client may
+// have that stops matching is sent as removed, holding only its id and type.
+type watchFilter struct {
+	apiContext *types.APIContext
+	schema     *types.Schema
+	opts       *types.QueryOptions
+	// sent holds whether the client has the object of an ID. A resumed client may have any object its
+	// previous stream sent, so IDs missing count as sent until they are removed.
+	sent    map[string]bool
+	resumed bool
+}
+
+func newWatchFilter(apiContext *types.APIContext, schema *types.Schema, opts *types.QueryOptions) *watchFilter {
+	return &watchFilter{
+		apiContext: apiContext,
+		schema:     schema,
+		opts:       opts,
+		sent:       map[string]bool{},
+		resumed:    opts.ResourceVersion != "",
+	}
+}
+
+// apply returns the event to send for item, or nil if the client isn't interested in it
+func (f *watchFilter) apply(item map[string]interface{}) map[string]interface{} {
+	if types.IsWatchControl(item) || (len(f.opts.Conditions) == 0 && f.opts.Namespaces == nil) {
+		return item
+	}
+
+	id := convert.ToString(item["id"])
+	if item[types.WatchRemoved] != true && f.matches(item) {
+		f.sent[id] = true
+		return item
+	}
+
+	sent, ok := f.sent[id]
+	if !sent && (ok || !f.resumed) {
+		return nil
+	}
+	f.sent[id] = false
+	return removed(item)
+}
+
+func (f *watchFilter) matches(item map[string]interface{}) bool {
+	if f.opts.Namespaces != nil {
+		namespace := convert.ToString(item["namespaceId"])
+		if namespace == "" {
+			namespace = convert.ToString(item["namespace"])
+		}
+		if !slice.ContainsString(f.opts.Namespaces, namespace) {
+			return false
+		}
+	}
+	if len(f.opts.Conditions) == 0 {
+		return true
+	}
+
+	return f.apiContext.FilterObject(&types.QueryOptions{
+		Conditions: f.opts.Conditions,
+	}, f.schema, item) != nil
+}
+
+// removed returns the removal event of item, leaving out the data the client may no longer see
+func removed(item map[string]interface{}) map[string]interface{} {
+	result := map[string]interface{}{
+		"id":               item["id"],
+		"type":             item["type"],
+		types.WatchRemoved: true,
+	}
+	if version, ok := item[types.ResourceFieldResourceVersion]; ok {
+		result[types.ResourceFieldResourceVersion] = version
+	}
+	return result
+}
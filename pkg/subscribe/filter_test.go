@@ -0,0 +1,96 @@
+package subscribe
+
+import (
+	"testing"
+
+	apihandler "github.com/rancher/norman/api/handler"
+	"github.com/rancher/norman/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchFilter(t *testing.T) {
+	apiContext := &types.APIContext{
+		QueryFilter: apihandler.QueryFilter,
+	}
+	schema := &types.Schema{
+		ID: "pod",
+		ResourceFields: map[string]types.Field{
+			"state": {Type: "string"},
+		},
+	}
+	opts := &types.QueryOptions{
+		Conditions: []*types.QueryCondition{
+			types.NewConditionFromString("state", types.ModifierNE, "removing"),
+		},
+	}
+	filter := newWatchFilter(apiContext, schema, opts)
+
+	pod := func(id, state string) map[string]interface{} {
+		return map[string]interface{}{
+			"id":                               id,
+			"type":                             "pod",
+			"state":                            state,
+			"secret":                           "value",
+			types.ResourceFieldResourceVersion: "12",
+		}
+	}
+
+	assert.Nil(t, filter.apply(pod("pod1", "removing")), "objects never sent aren't removed")
+
+	event := filter.apply(pod("pod1", "active"))
+	if assert.NotNil(t, event) {
+		assert.Nil(t, event[types.WatchRemoved])
+	}
+
+	assert.Equal(t, map[string]interface{}{
+		"id":                               "pod1",
+		"type":                             "pod",
+		types.ResourceFieldResourceVersion: "12",
+		types.WatchRemoved:                 true,
+	}, filter.apply(pod("pod1", "removing")), "objects that stop matching are removed without their data")
+	assert.Nil(t, filter.apply(pod("pod1", "removing")), "objects are removed once")
+
+	filter.apply(pod("pod2", "active"))
+	deleted := pod("pod2", "active")
+	deleted[types.WatchRemoved] = true
+	assert.Equal(t, true, filter.apply(deleted)[types.WatchRemoved])
+
+	deleted = pod("pod3", "active")
+	deleted[types.WatchRemoved] = true
+	assert.Nil(t, filter.apply(deleted))
+
+	bookmark := map[string]interface{}{types.WatchBookmark: true}
+	assert.Equal(t, bookmark, filter.apply(bookmark))
+
+	resumed := newWatchFilter(apiContext, schema, &types.QueryOptions{
+		Conditions:      opts.Conditions,
+		ResourceVersion: "10",
+	})
+	assert.Equal(t, true, resumed.apply(pod("pod1", "removing"))[types.WatchRemoved],
+		"resumed clients may have objects of their previous stream")
+	assert.Nil(t, resumed.apply(pod("pod1", "removing")), "objects are removed once")
+	assert.NotNil(t, resumed.apply(pod("pod1", "active")))
+	assert.Equal(t, true, resumed.apply(pod("pod1", "removing"))[types.WatchRemoved])
+}
+
+func TestWatchFilterNamespaces(t *testing.T) {
+	apiContext := &types.APIContext{
+		QueryFilter: apihandler.QueryFilter,
+	}
+	filter := newWatchFilter(apiContext, &types.Schema{ID: "pod"}, &types.QueryOptions{
+		Namespaces: []string{"shire"},
+	})
+
+	pod := func(id, namespace string) map[string]interface{} {
+		return map[string]interface{}{
+			"id":          id,
+			"type":        "pod",
+			"namespaceId": namespace,
+		}
+	}
+
+	assert.NotNil(t, filter.apply(pod("shire:frodo", "shire")))
+	assert.Nil(t, filter.apply(pod("mordor:gollum", "mordor")))
+	assert.Equal(t, true, filter.apply(pod("shire:frodo", "mordor"))[types.WatchRemoved],
+		"objects moving out of the namespaces are removed")
+}
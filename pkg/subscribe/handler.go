@@ -3,7 +3,11 @@ package subscribe
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -58,8 +62,20 @@ func handler(apiContext *types.APIContext) error {
 	if len(schemas) == 0 {
 		return httperror.NewAPIError(httperror.NotFound, "no resources types matched")
 	}
+	for _, schema := range schemas {
+		if err := parse.ValidateSchemaFilter(apiContext, schema); err != nil {
+			return err
+		}
+	}
+	resumed, err := resumeVersions(apiContext, schemas)
+	if err != nil {
+		return err
+	}
 
-	c, err := upgrader.Upgrade(apiContext.Response, apiContext.Request, nil)
+	cancelCtx, cancel := context.WithCancel(apiContext.Request.Context())
+	defer cancel()
+
+	c, err := newEventWriter(apiContext, cancel)
 	if err != nil {
 		return err
 	}
@@ -67,23 +83,12 @@ func handler(apiContext *types.APIContext) error {
 		_ = c.Close()
 	}()
 
-	cancelCtx, cancel := context.WithCancel(apiContext.Request.Context())
 	readerGroup, ctx := errgroup.WithContext(cancelCtx)
 	apiContext.Request = apiContext.Request.WithContext(ctx)
 
-	go func() {
-		for {
-			if _, _, err := c.NextReader(); err != nil {
-				cancel()
-				_ = c.Close()
-				break
-			}
-		}
-	}()
-
 	events := make(chan map[string]interface{})
 	for _, schema := range schemas {
-		streamStore(ctx, readerGroup, apiContext, schema, events)
+		streamStore(ctx, readerGroup, apiContext, schema, resumed[schema.ID], events)
 	}
 
 	go func() {
@@ -98,6 +103,12 @@ func handler(apiContext *types.APIContext) error {
 	t := time.NewTicker(5 * time.Second)
 	defer t.Stop()
 
+	versions := watchVersions{}
+	for schemaID, resourceVersion := range resumed {
+		versions[schemaID] = resourceVersion
+	}
+	bookmark := ""
+
 	done := false
 	for !done {
 		select {
@@ -107,11 +118,26 @@ func handler(apiContext *types.APIContext) error {
 				break
 			}
 
-			header := `{"name":"resource.change","data":`
-			if item[".removed"] == true {
-				header = `{"name":"resource.remove","data":`
+			versions.observe(item)
+
+			schemaID := convert.ToString(item["type"])
+			resourceVersion := convert.ToString(item[types.ResourceFieldResourceVersion])
+			if item[types.WatchResync] == true {
+				data, _ := json.Marshal(map[string]string{"type": schemaID})
+				if err := c.Write("resource.resync", "", data); err != nil {
+					cancel()
+				}
+				continue
+			}
+			if item[types.WatchBookmark] == true {
+				continue
 			}
-			schema := apiContext.Schemas.Schema(apiContext.Version, convert.ToString(item["type"]))
+
+			name := "resource.change"
+			if item[types.WatchRemoved] == true {
+				name = "resource.remove"
+			}
+			schema := apiContext.Schemas.Schema(apiContext.Version, schemaID)
 			if schema != nil {
 				buffer := &bytes.Buffer{}
 
@@ -120,21 +146,90 @@ func handler(apiContext *types.APIContext) error {
 					continue
 				}
 
-				if err := writeData(c, header, buffer.Bytes()); err != nil {
+				if err := c.Write(name, resourceVersion, buffer.Bytes()); err != nil {
 					cancel()
 				}
 			}
 		case <-t.C:
-			if err := writeData(c, `{"name":"ping","data":`, []byte("{}")); err != nil {
+			if err := c.Write("ping", "", []byte("{}")); err != nil {
 				cancel()
 			}
+			if resourceVersion := bookmarkVersion(schemas, versions); resourceVersion != bookmark {
+				bookmark = resourceVersion
+				if err := c.Write("resource.bookmark", resourceVersion, []byte("{}")); err != nil {
+					cancel()
+				}
+			}
 		}
 	}
 
-	// no point in ever returning null because the connection is hijacked and we can't write it
+	// no point in ever returning an error because the response has already been started
 	return nil
 }
 
+func eventHeader(name, resourceVersion string) string {
+	header, _ := json.Marshal(name)
+	if resourceVersion == "" {
+		return `{"name":` + string(header) + `,"data":`
+	}
+	version, _ := json.Marshal(resourceVersion)
+	return `{"name":` + string(header) + `,"resourceVersion":` + string(version) + `,"data":`
+}
+
+// watchVersions holds the latest resource version of each schema whose watch is past its initial list,
+// to send bookmarks a client can resume from. The objects of the initial list arrive in any order, so
+// their versions only count once the store sent a bookmark after it. A resumed watch has no initial list.
+type watchVersions map[string]string
+
+// observe updates the version of the schema of the watch event item
+func (v watchVersions) observe(item map[string]interface{}) {
+	schemaID := convert.ToString(item["type"])
+	if item[types.WatchResync] == true {
+		// the watch starts over with a new initial list
+		delete(v, schemaID)
+		return
+	}
+
+	resourceVersion := convert.ToString(item[types.ResourceFieldResourceVersion])
+	current, listed := v[schemaID]
+	if resourceVersion == "" || (!listed && item[types.WatchBookmark] != true) {
+		return
+	}
+	if !listed || newerVersion(resourceVersion, current) {
+		v[schemaID] = resourceVersion
+	}
+}
+
+// bookmarkVersion returns the bookmark holding the version of each schema as schema=version pairs
+// separated by commas. Every change of a schema up to its version has been sent, so a client can resume
+// the watch of each schema from it. It's empty until a version is known for every schema.
+func bookmarkVersion(schemas []*types.Schema, versions watchVersions) string {
+	var pairs []string
+	for _, schema := range schemas {
+		version, ok := versions[schema.ID]
+		if !ok {
+			return ""
+		}
+		pairs = append(pairs, schema.ID+"="+version)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// newerVersion returns true if the resource version left is newer than right. Kubernetes resource
+// versions are opaque, but increasing numbers in practice.
+func newerVersion(left, right string) bool {
+	leftInt, err := strconv.ParseUint(left, 10, 64)
+	if err != nil {
+		return false
+	}
+	rightInt, err := strconv.ParseUint(right, 10, 64)
+	if err != nil {
+		return true
+	}
+	return leftInt > rightInt
+}
+
 func writeData(c *websocket.Conn, header string, buf []byte) error {
 	messageWriter, err := c.NextWriter(websocket.TextMessage)
 	if err != nil {
@@ -153,9 +248,10 @@ func writeData(c *websocket.Conn, header string, buf []byte) error {
 	return messageWriter.Close()
 }
 
-func streamStore(ctx context.Context, eg *errgroup.Group, apiContext *types.APIContext, schema *types.Schema, result chan map[string]interface{}) {
+func streamStore(ctx context.Context, eg *errgroup.Group, apiContext *types.APIContext, schema *types.Schema, resourceVersion string, result chan map[string]interface{}) {
 	eg.Go(func() error {
 		opts := parse.QueryOptions(apiContext, schema)
+		opts.ResourceVersion = resourceVersion
 		events, err := schema.Store.Watch(apiContext, schema, &opts)
 		if err != nil || events == nil {
 			if err != nil {
@@ -166,8 +262,11 @@ func streamStore(ctx context.Context, eg *errgroup.Group, apiContext *types.APIC
 
 		logrus.Tracef("watching %s", schema.ID)
 
+		filter := newWatchFilter(apiContext, schema, &opts)
 		for e := range events {
-			result <- e
+			if e = filter.apply(e); e != nil {
+				result <- e
+			}
 		}
 
 		return errors.New("disconnect")
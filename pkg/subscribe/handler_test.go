@@ -0,0 +1,190 @@
+package subscribe
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/rancher/norman/api"
+	"github.com/rancher/norman/store/empty"
+	"github.com/rancher/norman/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBookmarkVersion(t *testing.T) {
+	schemas := []*types.Schema{{ID: "secret"}, {ID: "pod"}}
+
+	assert.Equal(t, "", bookmarkVersion(schemas, watchVersions{"pod": "10"}))
+	assert.Equal(t, "pod=10,secret=9", bookmarkVersion(schemas, watchVersions{"pod": "10", "secret": "9"}))
+
+	assert.True(t, newerVersion("100", "99"))
+	assert.True(t, newerVersion("1", ""))
+	assert.False(t, newerVersion("99", "100"))
+	assert.False(t, newerVersion("abc", ""))
+}
+
+func TestWatchVersions(t *testing.T) {
+	pod := func(resourceVersion string) map[string]interface{} {
+		return map[string]interface{}{"type": "pod", types.ResourceFieldResourceVersion: resourceVersion}
+	}
+	bookmark := func(resourceVersion string) map[string]interface{} {
+		return map[string]interface{}{"type": "pod", types.WatchBookmark: true, types.ResourceFieldResourceVersion: resourceVersion}
+	}
+
+	versions := watchVersions{}
+	versions.observe(pod("12"))
+	assert.Empty(t, versions, "objects of the initial list don't count")
+	versions.observe(bookmark("15"))
+	assert.Equal(t, "15", versions["pod"], "the initial list ends with a bookmark")
+	versions.observe(pod("17"))
+	assert.Equal(t, "17", versions["pod"])
+	versions.observe(bookmark("16"))
+	assert.Equal(t, "17", versions["pod"])
+
+	versions.observe(map[string]interface{}{"type": "pod", types.WatchResync: true})
+	assert.Empty(t, versions, "a resync starts a new initial list")
+}
+
+func TestResumeVersions(t *testing.T) {
+	resume := func(resourceVersion string, schemas ...*types.Schema) (map[string]string, error) {
+		return resumeVersions(&types.APIContext{
+			Query:   url.Values{"resourceVersion": []string{resourceVersion}},
+			Request: httptest.NewRequest(http.MethodGet, "/v1/subscribe", nil),
+		}, schemas)
+	}
+	pod, secret := &types.Schema{ID: "pod"}, &types.Schema{ID: "secret"}
+
+	versions, err := resume("", pod, secret)
+	require.NoError(t, err)
+	assert.Empty(t, versions)
+
+	versions, err = resume("pod=10,secret=9", pod, secret)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"pod": "10", "secret": "9"}, versions)
+
+	versions, err = resume("10", pod)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"pod": "10"}, versions)
+
+	_, err = resume("10", pod, secret)
+	assert.Error(t, err, "a plain version can't resume several schemas")
+	_, err = resume("pod=", pod)
+	assert.Error(t, err)
+}
+
+func TestEventHeader(t *testing.T) {
+	assert.Equal(t, `{"name":"resource.change","data":`, eventHeader("resource.change", ""))
+	assert.Equal(t, `{"name":"resource.remove","resourceVersion":"42","data":`, eventHeader("resource.remove", "42"))
+}
+
+type watchStore struct {
+	empty.Store
+	resourceVersion string
+}
+
+func (w *watchStore) Watch(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions) (chan map[string]interface{}, error) {
+	w.resourceVersion = opt.ResourceVersion
+	result := make(chan map[string]interface{}, 1)
+	result <- map[string]interface{}{
+		"id":                               "frodo",
+		"type":                             "hobbit",
+		"name":                             "frodo",
+		types.ResourceFieldResourceVersion: "43",
+	}
+	go func() {
+		<-apiContext.Request.Context().Done()
+		close(result)
+	}()
+	return result, nil
+}
+
+func TestServerSentEvents(t *testing.T) {
+	version := types.APIVersion{
+		Group:   "shire.cattle.io",
+		Version: "v1",
+		Path:    "/v1",
+	}
+
+	store := &watchStore{}
+	schemas := types.NewSchemas().
+		AddSchema(types.Schema{
+			ID:                "hobbit",
+			Version:           version,
+			CollectionMethods: []string{http.MethodGet},
+			ResourceMethods:   []string{http.MethodGet},
+			ResourceFields: map[string]types.Field{
+				"name": {Type: "string"},
+			},
+			CollectionFilters: map[string]types.Filter{
+				"name": {Modifiers: []types.ModifierType{types.ModifierEQ}},
+			},
+			Store: store,
+		})
+	Register(&version, schemas)
+
+	srv := api.NewAPIServer()
+	require.NoError(t, srv.AddSchemas(schemas))
+	server := httptest.NewServer(srv)
+	defer server.Close()
+
+	resp, err := server.Client().Get(server.URL + "/v1/subscribe?filter=age%3D1")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode, "filters are validated against the subscribed schemas")
+
+	// the filter expression is validated against the hobbit schema rather than the subscribe schema
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/v1/subscribe?filter=name%3Dfrodo", nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Last-Event-ID", "42")
+
+	resp, err = server.Client().Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(resp.Body)
+	var lines []string
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		if line == "\n" {
+			break
+		}
+		lines = append(lines, strings.TrimSuffix(line, "\n"))
+	}
+
+	require.Len(t, lines, 2)
+	assert.Equal(t, "event: resource.change", lines[0])
+	require.True(t, strings.HasPrefix(lines[1], "data: "))
+
+	event := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(lines[1], "data: ")), &event))
+	assert.Equal(t, "resource.change", event["name"])
+	assert.Equal(t, "43", event["resourceVersion"])
+	assert.Equal(t, "frodo", event["data"].(map[string]interface{})["name"])
+	assert.Equal(t, "42", store.resourceVersion)
+}
+
+func TestSSEWriter(t *testing.T) {
+	rw := httptest.NewRecorder()
+	w, err := newSSEWriter(rw)
+	require.NoError(t, err)
+
+	require.NoError(t, w.Write("ping", "", []byte("{}\n")))
+	require.NoError(t, w.Write("resource.bookmark", "42", []byte("{}")))
+
+	assert.Equal(t, "event: ping\ndata: {\"name\":\"ping\",\"data\":{}}\n\n"+
+		"event: resource.bookmark\nid: 42\ndata: {\"name\":\"resource.bookmark\",\"resourceVersion\":\"42\",\"data\":{}}\n\n",
+		rw.Body.String())
+
+	assert.True(t, acceptsEventStream(&http.Request{Header: http.Header{"Accept": []string{"application/json, text/event-stream;q=0.9"}}}))
+	assert.False(t, acceptsEventStream(&http.Request{Header: http.Header{}}))
+}
@@ -0,0 +1,158 @@
+package subscribe
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/websocket"
+	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/types"
+)
+
+const eventStreamContentType = "text/event-stream"
+
+// eventWriter sends the events of a subscription to the client. Every event is written as
+// {"name": name, "resourceVersion": resourceVersion, "data": data}.
+type eventWriter interface {
+	Write(name, resourceVersion string, data []byte) error
+	Close() error
+}
+
+// newEventWriter opens the transport the client asked for, server-sent events if it accepts
+// text/event-stream and a websocket otherwise. cancel is called once the client goes away.
+func newEventWriter(apiContext *types.APIContext, cancel context.CancelFunc) (eventWriter, error) {
+	if acceptsEventStream(apiContext.Request) {
+		return newSSEWriter(apiContext.Response)
+	}
+	return newWebsocketWriter(apiContext, cancel)
+}
+
+func acceptsEventStream(req *http.Request) bool {
+	for _, accept := range strings.Split(req.Header.Get("Accept"), ",") {
+		mediaType := strings.TrimSpace(strings.SplitN(accept, ";", 2)[0])
+		if strings.EqualFold(mediaType, eventStreamContentType) {
+			return true
+		}
+	}
+	return false
+}
+
+// resumeVersion returns the resource version to resume the subscription from. Server-sent event clients
+// send the id of the last event they received, which is the version of the last bookmark.
+func resumeVersion(apiContext *types.APIContext) string {
+	if resourceVersion := apiContext.Query.Get("resourceVersion"); resourceVersion != "" {
+		return resourceVersion
+	}
+	return apiContext.Request.Header.Get("Last-Event-ID")
+}
+
+// resumeVersions returns the resource version to resume the watch of each schema from, parsed from a
+// bookmark. A plain version, such as the one of a change event, only resumes a subscription to a single
+// schema, since the changes of the other schemas up to it may not have been sent.
+func resumeVersions(apiContext *types.APIContext, schemas []*types.Schema) (map[string]string, error) {
+	result := map[string]string{}
+	resourceVersion := resumeVersion(apiContext)
+	if resourceVersion == "" {
+		return result, nil
+	}
+
+	if !strings.Contains(resourceVersion, "=") {
+		if len(schemas) != 1 {
+			return nil, httperror.NewAPIError(httperror.InvalidOption,
+				"resourceVersion must be a bookmark to resume a subscription to several types")
+		}
+		result[schemas[0].ID] = resourceVersion
+		return result, nil
+	}
+
+	for _, pair := range strings.Split(resourceVersion, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, httperror.NewAPIError(httperror.InvalidFormat, "invalid resourceVersion "+resourceVersion)
+		}
+		result[parts[0]] = parts[1]
+	}
+	return result, nil
+}
+
+type websocketWriter struct {
+	conn *websocket.Conn
+}
+
+func newWebsocketWriter(apiContext *types.APIContext, cancel context.CancelFunc) (*websocketWriter, error) {
+	c, err := upgrader.Upgrade(apiContext.Response, apiContext.Request, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		for {
+			if _, _, err := c.NextReader(); err != nil {
+				cancel()
+				_ = c.Close()
+				break
+			}
+		}
+	}()
+
+	return &websocketWriter{conn: c}, nil
+}
+
+func (w *websocketWriter) Write(name, resourceVersion string, data []byte) error {
+	return writeData(w.conn, eventHeader(name, resourceVersion), data)
+}
+
+func (w *websocketWriter) Close() error {
+	return w.conn.Close()
+}
+
+// sseWriter writes events as server-sent events named after the event. Only bookmarks carry an id, so
+// the Last-Event-ID of a reconnecting client is always a version every change up to has been sent.
+type sseWriter struct {
+	rw      http.ResponseWriter
+	flusher http.Flusher
+}
+
+func newSSEWriter(rw http.ResponseWriter) (*sseWriter, error) {
+	flusher, ok := rw.(http.Flusher)
+	if !ok {
+		return nil, errors.New("streaming is not supported by the response writer")
+	}
+
+	rw.Header().Set("Content-Type", eventStreamContentType)
+	rw.Header().Set("Cache-Control", "no-cache")
+	rw.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	return &sseWriter{
+		rw:      rw,
+		flusher: flusher,
+	}, nil
+}
+
+func (w *sseWriter) Write(name, resourceVersion string, data []byte) error {
+	buffer := &bytes.Buffer{}
+	buffer.WriteString("event: " + name + "\n")
+	if name == "resource.bookmark" && resourceVersion != "" {
+		buffer.WriteString("id: " + resourceVersion + "\n")
+	}
+
+	message := eventHeader(name, resourceVersion) + string(bytes.TrimRight(data, "\n")) + "}"
+	for _, line := range strings.Split(message, "\n") {
+		buffer.WriteString("data: " + line + "\n")
+	}
+	buffer.WriteString("\n")
+
+	if _, err := w.rw.Write(buffer.Bytes()); err != nil {
+		return err
+	}
+	w.flusher.Flush()
+	return nil
+}
+
+func (w *sseWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,51 @@
+// Package tracing creates OpenTelemetry spans for the stages of handling an API request. Spans are
+// created with the tracer provider of the span already in the context, so only requests traced by the
+// API server are traced further down and no global tracer provider is required.
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/rancher/norman"
+
+// Tracer returns the norman tracer of provider, falling back to the global tracer provider
+func Tracer(provider trace.TracerProvider) trace.Tracer {
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	return provider.Tracer(instrumentationName)
+}
+
+// Start starts a child span of the span in ctx. Nothing is recorded if ctx isn't traced.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer(trace.SpanFromContext(ctx).TracerProvider()).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End ends span, recording err as its status
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Extract returns ctx with the remote span context sent in the headers of req
+func Extract(ctx context.Context, req *http.Request) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(req.Header))
+}
+
+// Inject returns the headers carrying the span context of ctx to a remote server
+func Inject(ctx context.Context) http.Header {
+	header := http.Header{}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+	return header
+}
@@ -12,6 +12,7 @@ import (
 	"golang.org/x/sync/errgroup"
 	apiext "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -21,6 +22,9 @@ import (
 type Factory struct {
 	eg           errgroup.Group
 	ClientGetter proxy.ClientGetter
+	// Schemas is used to resolve nested types of schemas with CRDValidation enabled when they are
+	// created through AssignStores or CreateCRDs
+	Schemas *types.Schemas
 }
 
 func NewFactoryFromClientGetter(clientGetter proxy.ClientGetter) *Factory {
@@ -56,7 +60,7 @@ func (f *Factory) BatchCreateCRDs(ctx context.Context, storageContext types.Stor
 			schemasToCreate = append(schemasToCreate, s)
 		}
 
-		err := f.AssignStores(ctx, storageContext, typer, schemasToCreate...)
+		err := f.assignStores(ctx, storageContext, typer, schemas, schemasToCreate...)
 		if err != nil {
 			return fmt.Errorf("creating CRD store %v", err)
 		}
@@ -66,7 +70,11 @@ func (f *Factory) BatchCreateCRDs(ctx context.Context, storageContext types.Stor
 }
 
 func (f *Factory) AssignStores(ctx context.Context, storageContext types.StorageContext, typer proxy.StoreTyper, schemas ...*types.Schema) error {
-	schemaStatus, err := f.CreateCRDs(ctx, storageContext, schemas...)
+	return f.assignStores(ctx, storageContext, typer, f.Schemas, schemas...)
+}
+
+func (f *Factory) assignStores(ctx context.Context, storageContext types.StorageContext, typer proxy.StoreTyper, allSchemas *types.Schemas, schemas ...*types.Schema) error {
+	schemaStatus, err := f.createCRDs(ctx, storageContext, allSchemas, schemas...)
 	if err != nil {
 		return err
 	}
@@ -92,6 +100,10 @@ func (f *Factory) AssignStores(ctx context.Context, storageContext types.Storage
 }
 
 func (f *Factory) CreateCRDs(ctx context.Context, storageContext types.StorageContext, schemas ...*types.Schema) (map[*types.Schema]*apiext.CustomResourceDefinition, error) {
+	return f.createCRDs(ctx, storageContext, f.Schemas, schemas...)
+}
+
+func (f *Factory) createCRDs(ctx context.Context, storageContext types.StorageContext, allSchemas *types.Schemas, schemas ...*types.Schema) (map[*types.Schema]*apiext.CustomResourceDefinition, error) {
 	schemaStatus := map[*types.Schema]*apiext.CustomResourceDefinition{}
 
 	apiClient, err := f.ClientGetter.APIExtClient(nil, storageContext)
@@ -105,7 +117,7 @@ func (f *Factory) CreateCRDs(ctx context.Context, storageContext types.StorageCo
 	}
 
 	for _, schema := range schemas {
-		crd, err := f.createCRD(ctx, apiClient, schema, ready)
+		crd, err := f.createCRD(ctx, apiClient, allSchemas, schema, ready)
 		if err != nil {
 			return nil, err
 		}
@@ -164,12 +176,20 @@ func (f *Factory) waitCRD(ctx context.Context, apiClient clientset.Interface, cr
 	})
 }
 
-func (f *Factory) createCRD(ctx context.Context, apiClient clientset.Interface, schema *types.Schema, ready map[string]*apiext.CustomResourceDefinition) (*apiext.CustomResourceDefinition, error) {
+func (f *Factory) createCRD(ctx context.Context, apiClient clientset.Interface, allSchemas *types.Schemas, schema *types.Schema, ready map[string]*apiext.CustomResourceDefinition) (*apiext.CustomResourceDefinition, error) {
 	plural := strings.ToLower(schema.PluralName)
 	name := strings.ToLower(plural + "." + schema.Version.Group)
 
+	openAPISchema := catchAllSchema()
+	if schema.CRDValidation {
+		openAPISchema = ValidationSchema(allSchemas, schema)
+	}
+
 	crd, ok := ready[name]
 	if ok {
+		if schema.CRDValidation {
+			return f.updateCRDSchema(ctx, apiClient, crd, schema.Version.Version, openAPISchema)
+		}
 		return crd, nil
 	}
 
@@ -184,14 +204,8 @@ func (f *Factory) createCRD(ctx context.Context, apiClient clientset.Interface,
 					Name:    schema.Version.Version,
 					Served:  true,
 					Storage: true,
-					// Using catch-all schema as it's required in v1 and we do not have enough info in `schema`
-					// Note catch-all schema used in Wrangler (open schema for "spec" and "status") is not good enough
-					// here as Norman CRDs often define direct fields
 					Schema: &apiext.CustomResourceValidation{
-						OpenAPIV3Schema: &apiext.JSONSchemaProps{
-							Type:                   "object",
-							XPreserveUnknownFields: &[]bool{true}[0],
-						},
+						OpenAPIV3Schema: openAPISchema,
 					},
 				},
 			},
@@ -216,6 +230,34 @@ func (f *Factory) createCRD(ctx context.Context, apiClient clientset.Interface,
 	return crd2, err
 }
 
+// updateCRDSchema sets openAPISchema as the validation schema of version of crd, if it differs
+func (f *Factory) updateCRDSchema(ctx context.Context, apiClient clientset.Interface, crd *apiext.CustomResourceDefinition, version string, openAPISchema *apiext.JSONSchemaProps) (*apiext.CustomResourceDefinition, error) {
+	index := -1
+	for i, crdVersion := range crd.Spec.Versions {
+		if crdVersion.Name == version {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		logrus.Warnf("CRD %s has no version %s to set the validation schema of", crd.Name, version)
+		return crd, nil
+	}
+
+	current := crd.Spec.Versions[index].Schema
+	if current != nil && equality.Semantic.DeepEqual(current.OpenAPIV3Schema, openAPISchema) {
+		return crd, nil
+	}
+
+	crd = crd.DeepCopy()
+	crd.Spec.Versions[index].Schema = &apiext.CustomResourceValidation{
+		OpenAPIV3Schema: openAPISchema,
+	}
+
+	logrus.Infof("Updating validation schema of version %s of CRD %s", version, crd.Name)
+	return apiClient.ApiextensionsV1().CustomResourceDefinitions().Update(ctx, crd, metav1.UpdateOptions{})
+}
+
 func (f *Factory) getReadyCRDs(ctx context.Context, apiClient clientset.Interface) (map[string]*apiext.CustomResourceDefinition, error) {
 	list, err := apiClient.ApiextensionsV1().CustomResourceDefinitions().List(ctx, metav1.ListOptions{})
 	if err != nil {
@@ -0,0 +1,37 @@
+package crd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiext "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestUpdateCRDSchema(t *testing.T) {
+	crd := &apiext.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "hobbits.shire.cattle.io"},
+		Spec: apiext.CustomResourceDefinitionSpec{
+			Versions: []apiext.CustomResourceDefinitionVersion{
+				{Name: "v1", Served: true, Storage: true},
+				{Name: "v2", Served: true},
+			},
+		},
+	}
+	client := fake.NewSimpleClientset(crd)
+	openAPISchema := &apiext.JSONSchemaProps{Type: "object"}
+
+	updated, err := (&Factory{}).updateCRDSchema(context.Background(), client, crd, "v2", openAPISchema)
+	require.NoError(t, err)
+	assert.Nil(t, updated.Spec.Versions[0].Schema, "other versions keep their schema")
+	if assert.NotNil(t, updated.Spec.Versions[1].Schema) {
+		assert.Equal(t, openAPISchema, updated.Spec.Versions[1].Schema.OpenAPIV3Schema)
+	}
+
+	unchanged, err := (&Factory{}).updateCRDSchema(context.Background(), client, crd, "v3", openAPISchema)
+	require.NoError(t, err)
+	assert.Same(t, crd, unchanged, "CRDs without the version aren't updated")
+}
@@ -0,0 +1,181 @@
+package crd
+
+import (
+	"encoding/json"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/rancher/norman/types"
+	"github.com/rancher/norman/types/definition"
+	apiext "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+// catchAllSchema is used for schemas that have not opted into validation and for types that can not
+// be resolved. A schema is required in v1 and the catch-all used in Wrangler (open schema for "spec"
+// and "status") is not good enough here as Norman CRDs often define direct fields.
+func catchAllSchema() *apiext.JSONSchemaProps {
+	return &apiext.JSONSchemaProps{
+		Type:                   "object",
+		XPreserveUnknownFields: &[]bool{true}[0],
+	}
+}
+
+// ValidationSchema builds a structural OpenAPI v3 schema for the object stored by the CRD backing
+// schema. Nested types are resolved through schemas, if schemas is nil or a type can not be found
+// the field is left open.
+func ValidationSchema(schemas *types.Schemas, schema *types.Schema) *apiext.JSONSchemaProps {
+	b := &validationBuilder{
+		schemas:  schemas,
+		version:  &schema.Version,
+		visiting: map[string]bool{},
+	}
+
+	props := b.object(internal(schema))
+	// metadata, apiVersion and kind are owned by the API server, the structural schema may only declare them
+	props.Properties["metadata"] = apiext.JSONSchemaProps{Type: "object"}
+	props.Properties["apiVersion"] = apiext.JSONSchemaProps{Type: "string"}
+	props.Properties["kind"] = apiext.JSONSchemaProps{Type: "string"}
+	props.Required = slices.DeleteFunc(props.Required, func(name string) bool {
+		return name == "metadata" || name == "apiVersion" || name == "kind"
+	})
+	props.Nullable = false
+	return props
+}
+
+type validationBuilder struct {
+	schemas  *types.Schemas
+	version  *types.APIVersion
+	visiting map[string]bool
+}
+
+func internal(schema *types.Schema) *types.Schema {
+	if schema.InternalSchema != nil {
+		return schema.InternalSchema
+	}
+	return schema
+}
+
+func (b *validationBuilder) object(schema *types.Schema) *apiext.JSONSchemaProps {
+	b.visiting[schema.ID] = true
+	defer delete(b.visiting, schema.ID)
+
+	props := &apiext.JSONSchemaProps{
+		Type:       "object",
+		Properties: map[string]apiext.JSONSchemaProps{},
+	}
+
+	for name, field := range schema.ResourceFields {
+		props.Properties[name] = *b.field(field)
+		if field.Required {
+			props.Required = append(props.Required, name)
+		}
+	}
+	sort.Strings(props.Required)
+
+	return props
+}
+
+func (b *validationBuilder) field(field types.Field) *apiext.JSONSchemaProps {
+	props := b.fieldType(field.Type)
+	props.Description = field.Description
+	props.Nullable = field.Nullable
+
+	switch props.Type {
+	case "string":
+		props.MinLength = field.MinLength
+		props.MaxLength = field.MaxLength
+		for _, option := range field.Options {
+			props.Enum = append(props.Enum, jsonValue(option))
+		}
+		if len(props.Enum) > 0 && field.Nullable {
+			// the builder accepts an empty value for nullable enums
+			props.Enum = append(props.Enum, jsonValue(""))
+		}
+		if field.ValidChars != "" {
+			props.Pattern = "^[" + charClass(field.ValidChars) + "]*$"
+		} else if field.InvalidChars != "" {
+			props.Pattern = "^[^" + charClass(field.InvalidChars) + "]*$"
+		}
+	case "integer", "number":
+		if field.Min != nil {
+			min := float64(*field.Min)
+			props.Minimum = &min
+		}
+		if field.Max != nil {
+			max := float64(*field.Max)
+			props.Maximum = &max
+		}
+	}
+
+	return props
+}
+
+func (b *validationBuilder) fieldType(fieldType string) *apiext.JSONSchemaProps {
+	switch {
+	case definition.IsArrayType(fieldType):
+		return &apiext.JSONSchemaProps{
+			Type: "array",
+			Items: &apiext.JSONSchemaPropsOrArray{
+				Schema: b.fieldType(definition.SubType(fieldType)),
+			},
+		}
+	case definition.IsMapType(fieldType):
+		return &apiext.JSONSchemaProps{
+			Type: "object",
+			AdditionalProperties: &apiext.JSONSchemaPropsOrBool{
+				Allows: true,
+				Schema: b.fieldType(definition.SubType(fieldType)),
+			},
+		}
+	case definition.IsReferenceType(fieldType):
+		return &apiext.JSONSchemaProps{Type: "string"}
+	}
+
+	switch fieldType {
+	case "string", "enum", "password", "dnsLabel", "dnsLabelRestricted", "hostname", "reference":
+		return &apiext.JSONSchemaProps{Type: "string"}
+	case "base64":
+		return &apiext.JSONSchemaProps{Type: "string", Format: "byte"}
+	case "date":
+		return &apiext.JSONSchemaProps{Type: "string", Format: "date-time"}
+	case "int":
+		return &apiext.JSONSchemaProps{Type: "integer"}
+	case "float":
+		return &apiext.JSONSchemaProps{Type: "number"}
+	case "boolean":
+		return &apiext.JSONSchemaProps{Type: "boolean"}
+	case "intOrString":
+		return &apiext.JSONSchemaProps{XIntOrString: true}
+	case "json":
+		return &apiext.JSONSchemaProps{XPreserveUnknownFields: &[]bool{true}[0]}
+	}
+
+	var schema *types.Schema
+	if b.schemas != nil {
+		schema = b.schemas.Schema(b.version, fieldType)
+	}
+	if schema == nil || b.visiting[schema.ID] {
+		// unknown or recursive types can't be expressed in a structural schema
+		return catchAllSchema()
+	}
+
+	return b.object(internal(schema))
+}
+
+func jsonValue(value string) apiext.JSON {
+	raw, _ := json.Marshal(value)
+	return apiext.JSON{Raw: raw}
+}
+
+func charClass(chars string) string {
+	buf := strings.Builder{}
+	for _, c := range chars {
+		switch c {
+		case '\\', ']', '[', '^', '-':
+			buf.WriteRune('\\')
+		}
+		buf.WriteRune(c)
+	}
+	return buf.String()
+}
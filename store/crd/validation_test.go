@@ -0,0 +1,103 @@
+package crd
+
+import (
+	"testing"
+
+	"github.com/rancher/norman/types"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiext "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+type Hobbit struct {
+	types.Namespaced
+
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec HobbitSpec `json:"spec"`
+}
+
+type HobbitSpec struct {
+	Name       string             `json:"name" norman:"required,minLength=1,maxLength=32,validChars=abc-]"`
+	Meal       string             `json:"meal,omitempty" norman:"options=breakfast|elevenses"`
+	Breakfasts int64              `json:"breakfasts,omitempty" norman:"min=1,max=7"`
+	Height     *float64           `json:"height,omitempty"`
+	Birthday   metav1.Time        `json:"birthday,omitempty"`
+	Pipe       intstr.IntOrString `json:"pipe,omitempty"`
+	Friends    []Friend           `json:"friends,omitempty"`
+	Labels     map[string]string  `json:"labels,omitempty"`
+	Extra      interface{}        `json:"extra,omitempty"`
+}
+
+type Friend struct {
+	Name    string   `json:"name,omitempty"`
+	Friends []Friend `json:"friends,omitempty"`
+}
+
+func TestValidationSchema(t *testing.T) {
+	version := types.APIVersion{
+		Group:   "shire.cattle.io",
+		Version: "v1",
+		Path:    "/v1",
+	}
+
+	schemas := types.NewSchemas().MustImport(&version, Hobbit{})
+	props := ValidationSchema(schemas, schemas.Schema(&version, "hobbit"))
+
+	internalProps := &apiextensions.JSONSchemaProps{}
+	assert.NoError(t, apiext.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(props, internalProps, nil))
+	structural, err := schema.NewStructural(internalProps)
+	assert.NoError(t, err)
+	assert.Empty(t, schema.ValidateStructural(nil, structural))
+
+	assert.Equal(t, "object", props.Properties["metadata"].Type)
+	assert.Equal(t, "string", props.Properties["kind"].Type)
+
+	spec := props.Properties["spec"]
+	assert.Equal(t, []string{"name"}, spec.Required)
+
+	name := spec.Properties["name"]
+	assert.Equal(t, "string", name.Type)
+	assert.Equal(t, int64(1), *name.MinLength)
+	assert.Equal(t, int64(32), *name.MaxLength)
+	assert.Equal(t, `^[abc\-\]]*$`, name.Pattern)
+
+	meal := spec.Properties["meal"]
+	assert.Equal(t, []apiext.JSON{{Raw: []byte(`"breakfast"`)}, {Raw: []byte(`"elevenses"`)}, {Raw: []byte(`""`)}}, meal.Enum)
+
+	breakfasts := spec.Properties["breakfasts"]
+	assert.Equal(t, "integer", breakfasts.Type)
+	assert.False(t, breakfasts.Nullable)
+	assert.Equal(t, float64(1), *breakfasts.Minimum)
+	assert.Equal(t, float64(7), *breakfasts.Maximum)
+
+	assert.Equal(t, "number", spec.Properties["height"].Type)
+	assert.Equal(t, "date-time", spec.Properties["birthday"].Format)
+	assert.True(t, spec.Properties["pipe"].XIntOrString)
+	assert.True(t, *spec.Properties["extra"].XPreserveUnknownFields)
+
+	friends := spec.Properties["friends"]
+	assert.Equal(t, "array", friends.Type)
+	assert.Equal(t, "string", friends.Items.Schema.Properties["name"].Type)
+	// recursive types are left open
+	assert.True(t, *friends.Items.Schema.Properties["friends"].Items.Schema.XPreserveUnknownFields)
+
+	labels := spec.Properties["labels"]
+	assert.Equal(t, "object", labels.Type)
+	assert.Equal(t, "string", labels.AdditionalProperties.Schema.Type)
+}
+
+func TestValidationSchemaUnknownTypes(t *testing.T) {
+	props := ValidationSchema(nil, &types.Schema{
+		ID: "hobbit",
+		ResourceFields: map[string]types.Field{
+			"spec": {Type: "hobbitSpec"},
+		},
+	})
+
+	assert.Equal(t, *catchAllSchema(), props.Properties["spec"])
+}
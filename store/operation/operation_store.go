@@ -0,0 +1,279 @@
+// Package operation keeps the operations tracking asynchronous actions in memory. Operations can be
+// read, listed and watched like any resource, and are forgotten some time after they finished.
+package operation
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/pkg/broadcast"
+	"github.com/rancher/norman/store/empty"
+	"github.com/rancher/norman/types"
+	"github.com/rancher/norman/types/convert"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+)
+
+const (
+	Running   = "running"
+	Succeeded = "succeeded"
+	Failed    = "failed"
+
+	// SchemaID is the ID of the schema of operations
+	SchemaID = "operation"
+
+	// DefaultRetention is how long finished operations are kept by default
+	DefaultRetention = time.Hour
+
+	userHeader = "Impersonate-User"
+)
+
+type contextKey struct{}
+
+type Store struct {
+	empty.Store
+	// Retention is how long finished operations are kept, DefaultRetention if zero
+	Retention time.Duration
+	// User returns the user an operation is started for and read by, each user only sees their own
+	// operations. The Impersonate-User header, as forwarded to the stores, is used if nil. Operations
+	// of anonymous requests, for which User returns "", can't be read by anyone.
+	User func(apiContext *types.APIContext) string
+
+	lock        sync.Mutex
+	operations  map[string]*Operation
+	broadcaster broadcast.Broadcaster
+	events      chan map[string]interface{}
+	watched     bool
+}
+
+// Operation tracks an asynchronous action started with Store.Start
+type Operation struct {
+	store    *Store
+	user     string
+	data     map[string]interface{}
+	finished time.Time
+}
+
+func NewStore() *Store {
+	return &Store{
+		operations: map[string]*Operation{},
+		// progress updates of an operation nobody read yet replace each other
+		broadcaster: broadcast.Broadcaster{Policy: broadcast.Coalesce},
+		events:      make(chan map[string]interface{}),
+	}
+}
+
+// Start creates a running operation for the action of apiContext
+func (s *Store) Start(apiContext *types.APIContext, action string) *Operation {
+	now := time.Now()
+	op := &Operation{
+		store: s,
+		user:  s.user(apiContext),
+		data: map[string]interface{}{
+			"id":           "op-" + utilrand.String(10),
+			"type":         SchemaID,
+			"action":       action,
+			"resourceType": apiContext.Type,
+			"resourceId":   apiContext.ID,
+			"status":       Running,
+			"progress":     int64(0),
+			"created":      convert.ToString(now.UTC()),
+		},
+	}
+
+	s.lock.Lock()
+	s.prune(now)
+	s.operations[op.ID()] = op
+	s.lock.Unlock()
+
+	s.notify(op.Data())
+	return op
+}
+
+func (s *Store) user(apiContext *types.APIContext) string {
+	if s.User != nil {
+		return s.User(apiContext)
+	}
+	if apiContext.Request == nil {
+		return ""
+	}
+	return apiContext.Request.Header.Get(userHeader)
+}
+
+// visible returns the operation with id if it was started by the user of apiContext
+func (s *Store) visible(apiContext *types.APIContext, id string) (*Operation, bool) {
+	s.lock.Lock()
+	op, ok := s.operations[id]
+	s.lock.Unlock()
+
+	if !ok || op.user == "" || op.user != s.user(apiContext) {
+		return nil, false
+	}
+	return op, true
+}
+
+// prune forgets the operations that finished longer than the retention ago
+func (s *Store) prune(now time.Time) {
+	retention := s.Retention
+	if retention <= 0 {
+		retention = DefaultRetention
+	}
+	for id, op := range s.operations {
+		if !op.finished.IsZero() && now.Sub(op.finished) > retention {
+			delete(s.operations, id)
+		}
+	}
+}
+
+// notify sends a change of an operation to the watchers, once there were any
+func (s *Store) notify(data map[string]interface{}) {
+	s.lock.Lock()
+	watched := s.watched
+	s.lock.Unlock()
+
+	if watched {
+		s.events <- data
+	}
+}
+
+func (s *Store) ByID(apiContext *types.APIContext, schema *types.Schema, id string) (map[string]interface{}, error) {
+	op, ok := s.visible(apiContext, id)
+	if !ok {
+		return nil, httperror.NewAPIError(httperror.NotFound, "operation "+id+" not found")
+	}
+	return op.Data(), nil
+}
+
+func (s *Store) List(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions) ([]map[string]interface{}, error) {
+	user := s.user(apiContext)
+
+	s.lock.Lock()
+	ops := make([]*Operation, 0, len(s.operations))
+	for _, op := range s.operations {
+		if user != "" && op.user == user {
+			ops = append(ops, op)
+		}
+	}
+	s.lock.Unlock()
+
+	result := make([]map[string]interface{}, 0, len(ops))
+	for _, op := range ops {
+		result = append(result, op.Data())
+	}
+	return result, nil
+}
+
+func (s *Store) Watch(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions) (chan map[string]interface{}, error) {
+	c, err := s.broadcaster.Subscribe(apiContext.Request.Context(), func() (chan map[string]interface{}, error) {
+		return s.events, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.lock.Lock()
+	s.watched = true
+	s.lock.Unlock()
+
+	result := make(chan map[string]interface{})
+	go func() {
+		defer close(result)
+		for data := range c {
+			if _, ok := s.visible(apiContext, convert.ToString(data["id"])); !ok {
+				continue
+			}
+			select {
+			case result <- data:
+			case <-apiContext.Request.Context().Done():
+				return
+			}
+		}
+	}()
+	return result, nil
+}
+
+func (o *Operation) ID() string {
+	return o.data["id"].(string)
+}
+
+// Data returns a copy of the operation resource
+func (o *Operation) Data() map[string]interface{} {
+	o.store.lock.Lock()
+	defer o.store.lock.Unlock()
+
+	data := make(map[string]interface{}, len(o.data))
+	for k, v := range o.data {
+		data[k] = v
+	}
+	return data
+}
+
+// SetProgress records how far a running operation got, progress is a percentage
+func (o *Operation) SetProgress(progress int64, message string) {
+	o.update(func(data map[string]interface{}) {
+		data["progress"] = progress
+		if message == "" {
+			delete(data, "message")
+		} else {
+			data["message"] = message
+		}
+	})
+}
+
+// Succeed finishes the operation with the output of the action, of type resultType
+func (o *Operation) Succeed(result interface{}, resultType string) {
+	o.update(func(data map[string]interface{}) {
+		data["status"] = Succeeded
+		data["progress"] = int64(100)
+		if result != nil {
+			data["result"] = result
+		}
+		if resultType != "" {
+			data["resultType"] = resultType
+		}
+	})
+}
+
+// Fail finishes the operation with the error response of the action
+func (o *Operation) Fail(apiError interface{}) {
+	o.update(func(data map[string]interface{}) {
+		data["status"] = Failed
+		data["error"] = apiError
+	})
+}
+
+func (o *Operation) update(f func(data map[string]interface{})) {
+	o.store.lock.Lock()
+	if o.data["status"] != Running {
+		o.store.lock.Unlock()
+		return
+	}
+	f(o.data)
+	if o.data["status"] != Running {
+		o.finished = time.Now()
+		o.data["finished"] = convert.ToString(o.finished.UTC())
+	}
+	o.store.lock.Unlock()
+
+	o.store.notify(o.Data())
+}
+
+// NewContext returns ctx carrying op, for the action handler running it
+func NewContext(ctx context.Context, op *Operation) context.Context {
+	return context.WithValue(ctx, contextKey{}, op)
+}
+
+// FromContext returns the operation the action handler of ctx runs in, if it runs asynchronously
+func FromContext(ctx context.Context) *Operation {
+	op, _ := ctx.Value(contextKey{}).(*Operation)
+	return op
+}
+
+// SetProgress records the progress of the operation an action handler runs in. Nothing is recorded if
+// the action doesn't run asynchronously, so handlers can report progress either way.
+func SetProgress(apiContext *types.APIContext, progress int64, message string) {
+	if op := FromContext(apiContext.Request.Context()); op != nil {
+		op.SetProgress(progress, message)
+	}
+}
@@ -0,0 +1,99 @@
+package operation
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rancher/norman/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	store := NewStore()
+	req := httptest.NewRequest("POST", "/v1/hobbits/frodo?action=walk", nil).WithContext(ctx)
+	req.Header.Set("Impersonate-User", "frodo")
+	apiContext := &types.APIContext{
+		Type:    "hobbit",
+		ID:      "frodo",
+		Request: req,
+	}
+
+	events, err := store.Watch(apiContext, nil, &types.QueryOptions{})
+	require.NoError(t, err)
+
+	op := store.Start(apiContext, "walk")
+	op.Succeed(map[string]interface{}{"destination": "mordor"}, "journey")
+	op.SetProgress(10, "ignored once finished")
+
+	var last map[string]interface{}
+	for last["status"] != Succeeded {
+		select {
+		case last = <-events:
+			assert.Equal(t, op.ID(), last["id"])
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for the operation to succeed")
+		}
+	}
+	assert.Equal(t, int64(100), last["progress"])
+	assert.Equal(t, "journey", last["resultType"])
+
+	data, err := store.ByID(apiContext, nil, op.ID())
+	require.NoError(t, err)
+	assert.Equal(t, last, data)
+}
+
+func TestPrune(t *testing.T) {
+	store := NewStore()
+	store.Retention = time.Millisecond
+	req := httptest.NewRequest("POST", "/v1/hobbits?action=gather", nil)
+	req.Header.Set("Impersonate-User", "frodo")
+	apiContext := &types.APIContext{
+		Type:    "hobbit",
+		Request: req,
+	}
+
+	finished := store.Start(apiContext, "gather")
+	finished.Fail(map[string]interface{}{"code": "Conflict"})
+	running := store.Start(apiContext, "gather")
+
+	time.Sleep(2 * time.Millisecond)
+	store.Start(apiContext, "gather")
+
+	_, err := store.ByID(apiContext, nil, finished.ID())
+	assert.Error(t, err, "finished operations are forgotten after the retention")
+	_, err = store.ByID(apiContext, nil, running.ID())
+	assert.NoError(t, err, "running operations are kept")
+}
+
+func TestUser(t *testing.T) {
+	store := NewStore()
+	apiContext := func(user string) *types.APIContext {
+		req := httptest.NewRequest("POST", "/v1/hobbits?action=gather", nil)
+		req.Header.Set("Impersonate-User", user)
+		return &types.APIContext{Type: "hobbit", Request: req}
+	}
+
+	op := store.Start(apiContext("frodo"), "gather")
+
+	_, err := store.ByID(apiContext("frodo"), nil, op.ID())
+	assert.NoError(t, err)
+	_, err = store.ByID(apiContext("sam"), nil, op.ID())
+	assert.Error(t, err, "operations of other users aren't found")
+
+	list, err := store.List(apiContext("sam"), nil, &types.QueryOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	anonymous := store.Start(apiContext(""), "gather")
+	_, err = store.ByID(apiContext(""), nil, anonymous.ID())
+	assert.Error(t, err, "operations of anonymous requests can't be read")
+	list, err = store.List(apiContext(""), nil, &types.QueryOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
@@ -10,6 +10,10 @@ type errorStore struct {
 	types.Store
 }
 
+func (e *errorStore) SupportsDryRun() bool {
+	return types.SupportsDryRun(e.Store)
+}
+
 func (e *errorStore) ByID(apiContext *types.APIContext, schema *types.Schema, id string) (map[string]interface{}, error) {
 	data, err := e.Store.ByID(apiContext, schema, id)
 	return data, translateError(err)
@@ -13,12 +13,16 @@ import (
 	"github.com/rancher/norman/httperror"
 	"github.com/rancher/norman/objectclient/dynamic"
 	"github.com/rancher/norman/pkg/broadcast"
+	"github.com/rancher/norman/pkg/metrics"
+	"github.com/rancher/norman/pkg/tracing"
 	"github.com/rancher/norman/restwatch"
 	"github.com/rancher/norman/types"
 	"github.com/rancher/norman/types/convert"
 	"github.com/rancher/norman/types/convert/merge"
 	"github.com/rancher/norman/types/values"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/sync/errgroup"
 	"k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -134,7 +138,7 @@ func NewProxyStore(ctx context.Context, clientGetter ClientGetter, storageContex
 	}
 
 	return &errorStore{
-		Store: &Store{
+		Store: metrics.InstrumentStore("proxy", &Store{
 			clientGetter:   clientGetter,
 			storageContext: storageContext,
 			prefix:         prefix,
@@ -149,7 +153,7 @@ func NewProxyStore(ctx context.Context, clientGetter ClientGetter, storageContex
 			close:        ctx,
 			broadcasters: map[rest.Interface]*broadcast.Broadcaster{},
 			typer:        typer,
-		},
+		}),
 	}
 }
 
@@ -174,7 +178,20 @@ func (s *Store) doAuthed(apiContext *types.APIContext, request *rest.Request) re
 		}
 	}
 
-	return request.Do(apiContext.Request.Context())
+	ctx, span := s.startSpan(apiContext.Request.Context(), request)
+	result := request.Do(ctx)
+	tracing.End(span, result.Error())
+	return result
+}
+
+// startSpan starts the span of a Kubernetes request and passes its context on in the request headers
+func (s *Store) startSpan(ctx context.Context, request *rest.Request) (context.Context, trace.Span) {
+	ctx, span := tracing.Start(ctx, "kubernetes "+s.resourcePlural,
+		attribute.String("url.full", request.URL().String()))
+	for name, values := range tracing.Inject(ctx) {
+		request.SetHeader(name, values...)
+	}
+	return ctx, span
 }
 
 func (s *Store) k8sClient(apiContext *types.APIContext) (rest.Interface, error) {
@@ -234,17 +251,33 @@ func (s *Store) Context() types.StorageContext {
 
 func (s *Store) List(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions) ([]map[string]interface{}, error) {
 	result := []map[string]interface{}{}
+	listOpts, exact := listOptions(schema, opt)
 
 	// if there are no namespaces field in options, a single request is made
 	if opt == nil || opt.Namespaces == nil {
 		ns := getNamespace(apiContext, opt)
 		resultList := s.getListStruct()
 
-		err := s.retryList(ns, apiContext, resultList)
+		pagination := nativePagination(schema, opt, exact)
+		if pagination != nil {
+			listOpts.Limit = *pagination.Limit
+			listOpts.Continue = pagination.Marker
+		}
+
+		err := s.retryList(ns, listOpts, apiContext, resultList)
 		if err != nil {
 			return nil, err
 		}
 
+		if pagination != nil {
+			if err := setContinue(pagination, resultList); err != nil {
+				return nil, err
+			}
+			// the pagination is replaced rather than changed, the parsed one may be shared
+			opt.Pagination = pagination
+			apiContext.Pagination = pagination
+		}
+
 		collectionResults, _ := s.collectionFromInternal(resultList, apiContext, schema)
 		result = append(result, collectionResults...)
 	} else {
@@ -259,7 +292,7 @@ func (s *Store) List(apiContext *types.APIContext, schema *types.Schema, opt *ty
 			errGroup.Go(func() error {
 				resultList := s.getListStruct()
 
-				err := s.retryList(nsCopy, apiContext, resultList)
+				err := s.retryList(nsCopy, listOpts, apiContext, resultList)
 				if err != nil {
 					return err
 				}
@@ -280,16 +313,19 @@ func (s *Store) List(apiContext *types.APIContext, schema *types.Schema, opt *ty
 	return apiContext.AccessControl.FilterList(apiContext, schema, result, s.authContext), nil
 }
 
-func (s *Store) retryList(namespace string, apiContext *types.APIContext, resultList runtime.Object) error {
+func (s *Store) retryList(namespace string, listOpts *metav1.ListOptions, apiContext *types.APIContext, resultList runtime.Object) error {
 	k8sClient, err := s.k8sClient(apiContext)
 	if err != nil {
 		return err
 	}
 
 	for i := 0; i < 3; i++ {
-		req := s.common(namespace, k8sClient.Get())
+		req := s.common(namespace, k8sClient.Get()).
+			VersionedParams(listOpts, metav1.ParameterCodec)
 		start := time.Now()
-		err = req.Do(apiContext.Request.Context()).Into(resultList)
+		ctx, span := s.startSpan(apiContext.Request.Context(), req)
+		err = req.Do(ctx).Into(resultList)
+		tracing.End(span, err)
 		logrus.Tracef("LIST: %v, %v", time.Since(start), s.resourcePlural)
 		if err != nil {
 			if i < 2 && strings.Contains(err.Error(), "Client.Timeout exceeded") {
@@ -320,12 +356,24 @@ func shouldExpireAccessControl(apiContext *types.APIContext) bool {
 }
 
 func (s *Store) Watch(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions) (chan map[string]interface{}, error) {
-	c, err := s.shareWatch(apiContext, schema, opt)
+	var (
+		c   chan map[string]interface{}
+		err error
+	)
+	if opt != nil && opt.ResourceVersion != "" {
+		// a resumed watch starts at its own version, so it can't be shared
+		c, err = s.realWatch(apiContext, schema, opt)
+	} else {
+		c, err = s.shareWatch(apiContext, schema, opt)
+	}
 	if err != nil {
 		return nil, err
 	}
 
 	return convert.Chan(c, func(data map[string]interface{}) map[string]interface{} {
+		if types.IsWatchControl(data) {
+			return data
+		}
 		if shouldExpireAccessControl(apiContext) {
 			apiContext.ExpireAccessControl(schema)
 		}
@@ -345,16 +393,30 @@ func (s *Store) realWatch(apiContext *types.APIContext, schema *types.Schema, op
 		k8sClient = watchClient.WatchClient()
 	}
 
+	resourceVersion := "0"
+	if opt.ResourceVersion != "" {
+		resourceVersion = opt.ResourceVersion
+	}
+
 	timeout := int64(60 * 30)
 	req := s.common(namespace, k8sClient.Get())
 	req.VersionedParams(&metav1.ListOptions{
-		Watch:           true,
-		TimeoutSeconds:  &timeout,
-		ResourceVersion: "0",
+		Watch:               true,
+		TimeoutSeconds:      &timeout,
+		ResourceVersion:     resourceVersion,
+		AllowWatchBookmarks: true,
 	}, metav1.ParameterCodec)
 
-	body, err := req.Stream(s.close)
-	if err != nil {
+	ctx := apiContext.Request.Context()
+	_, span := s.startSpan(ctx, req)
+	body, err := req.Stream(ctx)
+	tracing.End(span, err)
+	if isExpired(err) {
+		result := make(chan map[string]interface{}, 1)
+		result <- resyncEvent(schema)
+		close(result)
+		return result, nil
+	} else if err != nil {
 		return nil, err
 	}
 
@@ -362,7 +424,7 @@ func (s *Store) realWatch(apiContext *types.APIContext, schema *types.Schema, op
 	decoder := streaming.NewDecoder(framer, &unstructuredDecoder{})
 	watcher := watch.NewStreamWatcher(restclientwatch.NewDecoder(decoder, &unstructuredDecoder{}), &errorReporter{})
 
-	watchingContext, cancelWatchingContext := context.WithCancel(s.close)
+	watchingContext, cancelWatchingContext := context.WithCancel(ctx)
 	go func() {
 		<-watchingContext.Done()
 		logrus.Tracef("stopping watcher for %s", schema.ID)
@@ -375,11 +437,28 @@ func (s *Store) realWatch(apiContext *types.APIContext, schema *types.Schema, op
 			if data, ok := event.Object.(*metav1.Status); ok {
 				// just logging it, keeping the same behavior as before
 				logrus.Tracef("watcher status for %s: %s", schema.ID, data.Message)
+			} else if event.Type == watch.Error {
+				err := errors.FromObject(event.Object)
+				logrus.Tracef("watcher error for %s: %v", schema.ID, err)
+				if isExpired(err) {
+					result <- resyncEvent(schema)
+				}
+			} else if event.Type == watch.Bookmark {
+				data := event.Object.(*unstructured.Unstructured)
+				result <- map[string]interface{}{
+					"type":                             schema.ID,
+					types.WatchBookmark:                true,
+					types.ResourceFieldResourceVersion: data.GetResourceVersion(),
+				}
 			} else {
 				data := event.Object.(*unstructured.Unstructured)
+				resourceVersion := data.GetResourceVersion()
 				s.fromInternal(apiContext, schema, data.Object)
+				if data.Object != nil {
+					data.Object[types.ResourceFieldResourceVersion] = resourceVersion
+				}
 				if event.Type == watch.Deleted && data.Object != nil {
-					data.Object[".removed"] = true
+					data.Object[types.WatchRemoved] = true
 				}
 				result <- data.Object
 			}
@@ -392,6 +471,18 @@ func (s *Store) realWatch(apiContext *types.APIContext, schema *types.Schema, op
 	return result, nil
 }
 
+// isExpired returns true if err means the requested resource version is too old to watch from
+func isExpired(err error) bool {
+	return errors.IsResourceExpired(err) || errors.IsGone(err)
+}
+
+func resyncEvent(schema *types.Schema) map[string]interface{} {
+	return map[string]interface{}{
+		"type":            schema.ID,
+		types.WatchResync: true,
+	}
+}
+
 type unstructuredDecoder struct {
 }
 
@@ -454,6 +545,7 @@ func (s *Store) Create(apiContext *types.APIContext, schema *types.Schema, data
 		Body(&unstructured.Unstructured{
 			Object: data,
 		})
+	dryRun(apiContext, req)
 
 	_, result, err := s.singleResult(apiContext, schema, req)
 	return result, err
@@ -491,6 +583,9 @@ func (s *Store) Update(apiContext *types.APIContext, schema *types.Schema, data
 		return nil, err
 	}
 
+	// with If-Match the update must be based on the given version, so a conflict isn't retried
+	expectedVersion := types.ETagVersion(apiContext.Request.Header.Get("If-Match"))
+
 	for i := 0; i < 5; i++ {
 		req := s.common(namespace, k8sClient.Get()).
 			Name(id)
@@ -500,8 +595,13 @@ func (s *Store) Update(apiContext *types.APIContext, schema *types.Schema, data
 			return nil, rawErr
 		}
 
-		existing = merge.APIUpdateMerge(schema.InternalSchema, apiContext.Schemas, existing, data, apiContext.Option("replace") == "true")
+		// a PATCH sends the complete patched resource, so it replaces like a PUT with _replace
+		replace := apiContext.Option("replace") == "true" || apiContext.Method == http.MethodPatch
+		existing = merge.APIUpdateMerge(schema.InternalSchema, apiContext.Schemas, existing, data, replace)
 
+		if expectedVersion != "" {
+			resourceVersion = expectedVersion
+		}
 		values.PutValue(existing, resourceVersion, "metadata", "resourceVersion")
 		values.PutValue(existing, namespace, "metadata", "namespace")
 		values.PutValue(existing, id, "metadata", "name")
@@ -511,9 +611,10 @@ func (s *Store) Update(apiContext *types.APIContext, schema *types.Schema, data
 				Object: existing,
 			}).
 			Name(id)
+		dryRun(apiContext, req)
 
 		_, result, err = s.singleResult(apiContext, schema, req)
-		if errors.IsConflict(err) {
+		if errors.IsConflict(err) && expectedVersion == "" {
 			continue
 		}
 		return result, err
@@ -533,6 +634,14 @@ func (s *Store) Delete(apiContext *types.APIContext, schema *types.Schema, id st
 	if err != nil {
 		return nil, err
 	}
+	if apiContext.Option("dryRun") == "true" {
+		options.DryRun = []string{metav1.DryRunAll}
+	}
+	if version := types.ETagVersion(apiContext.Request.Header.Get("If-Match")); version != "" {
+		options.Preconditions = &metav1.Preconditions{
+			ResourceVersion: &version,
+		}
+	}
 	req := s.common(namespace, k8sClient.Delete()).
 		Body(options).
 		Name(name)
@@ -549,12 +658,27 @@ func (s *Store) Delete(apiContext *types.APIContext, schema *types.Schema, id st
 	return obj, nil
 }
 
+// SupportsDryRun is true, as the dryRun option is passed on to Kubernetes
+func (s *Store) SupportsDryRun() bool {
+	return true
+}
+
+// dryRun makes Kubernetes only validate the change of req if the dryRun option is set
+func dryRun(apiContext *types.APIContext, req *rest.Request) {
+	if apiContext.Option("dryRun") == "true" {
+		req.Param("dryRun", metav1.DryRunAll)
+	}
+}
+
 func (s *Store) singleResult(apiContext *types.APIContext, schema *types.Schema, req *rest.Request) (string, map[string]interface{}, error) {
 	version, data, err := s.singleResultRaw(apiContext, schema, req)
 	if err != nil {
 		return "", nil, err
 	}
 	s.fromInternal(apiContext, schema, data)
+	if data != nil && version != "" {
+		data[types.ResourceFieldResourceVersion] = version
+	}
 	return version, data, nil
 }
 
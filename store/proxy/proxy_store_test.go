@@ -2,6 +2,7 @@ package proxy
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/json"
 	"io"
 	"net/http"
@@ -9,12 +10,15 @@ import (
 	"testing"
 	"time"
 
+	"github.com/rancher/norman/api/handler"
 	"github.com/rancher/norman/authorization"
 	"github.com/rancher/norman/types"
+	"github.com/rancher/norman/types/values"
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	"k8s.io/client-go/rest"
@@ -260,3 +264,130 @@ func Test_shouldExpireAccessControl(t *testing.T) {
 		})
 	}
 }
+
+type selectorMapper struct{}
+
+func (selectorMapper) FromInternal(data map[string]interface{}) {}
+
+func (selectorMapper) ToInternal(data map[string]interface{}) error {
+	for field, path := range map[string][]string{
+		"name":        {"metadata", "name"},
+		"namespaceId": {"metadata", "namespace"},
+		"app":         {"metadata", "labels", "app"},
+	} {
+		if v, ok := values.RemoveValue(data, field); ok {
+			values.PutValue(data, v, path...)
+		}
+	}
+	return nil
+}
+
+func (selectorMapper) ModifySchema(schema *types.Schema, schemas *types.Schemas) error {
+	return nil
+}
+
+// panicMapper panics like mappers expecting fields of a complete object do
+type panicMapper struct {
+	selectorMapper
+}
+
+func (panicMapper) ToInternal(data map[string]interface{}) error {
+	_ = data["spec"].(map[string]interface{})["replicas"]
+	return nil
+}
+
+func TestListOptionsMapperPanic(t *testing.T) {
+	opts, exact := listOptions(&types.Schema{ID: "deployment", Mapper: panicMapper{}}, &types.QueryOptions{
+		Conditions: []*types.QueryCondition{types.EQ("name", "test1")},
+	})
+	assert.Empty(t, opts.FieldSelector)
+	assert.False(t, exact, "the condition is left to the filtering in memory")
+}
+
+func TestListOptions(t *testing.T) {
+	schema := &types.Schema{
+		Mapper: selectorMapper{},
+	}
+
+	tests := []struct {
+		name       string
+		conditions []*types.QueryCondition
+		labels     string
+		fields     string
+		inexact    bool
+	}{
+		{
+			name: "labels",
+			conditions: []*types.QueryCondition{
+				types.NewConditionFromString("app", types.ModifierIn, "web", "db"),
+			},
+			labels: "app in (db,web)",
+		},
+		{
+			name: "name and namespace",
+			conditions: []*types.QueryCondition{
+				types.EQ("name", "test1"),
+				types.NewConditionFromString("namespaceId", types.ModifierNE, "kube-system"),
+			},
+			fields: "metadata.name=test1,metadata.namespace!=kube-system",
+		},
+		{
+			name: "not translatable",
+			conditions: []*types.QueryCondition{
+				types.NewConditionFromString("name", types.ModifierIn, "test1", "test2"),
+				types.NewConditionFromString("app", types.ModifierPrefix, "w"),
+				types.EQ("app", ""),
+				types.EQ("app", "not a label value"),
+				types.EQ("state", "active"),
+			},
+			inexact: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, exact := listOptions(schema, &types.QueryOptions{Conditions: tt.conditions})
+			assert.Equal(t, tt.labels, opts.LabelSelector)
+			assert.Equal(t, tt.fields, opts.FieldSelector)
+			assert.Equal(t, !tt.inexact, exact)
+		})
+	}
+}
+
+func TestNativePagination(t *testing.T) {
+	limit := int64(10)
+	zero := int64(0)
+	schema := &types.Schema{NativePagination: true}
+	token := base64.RawURLEncoding.EncodeToString([]byte(`{"v":"meta.k8s.io/v1","rv":12,"start":"frodo/"}`))
+
+	assert.Nil(t, nativePagination(schema, &types.QueryOptions{}, true))
+	assert.Nil(t, nativePagination(schema, &types.QueryOptions{Pagination: &types.Pagination{Limit: &zero}}, true))
+	assert.Nil(t, nativePagination(schema, &types.QueryOptions{Pagination: &types.Pagination{Limit: &limit}}, false))
+	assert.Nil(t, nativePagination(schema, &types.QueryOptions{
+		Pagination: &types.Pagination{Limit: &limit},
+		Sort:       types.Sort{Name: "name"},
+	}, true))
+	assert.Nil(t, nativePagination(schema, &types.QueryOptions{
+		Pagination: &types.Pagination{Limit: &limit},
+		Sort:       types.Sort{Order: types.DESC},
+	}, true))
+	assert.Nil(t, nativePagination(&types.Schema{}, &types.QueryOptions{Pagination: &types.Pagination{Limit: &limit}}, true),
+		"native pagination is opt-in")
+	assert.Nil(t, nativePagination(schema, &types.QueryOptions{Pagination: &types.Pagination{Limit: &limit, Marker: "frodo"}}, true),
+		"markers that aren't continue tokens are paginated in memory")
+
+	parsed := &types.Pagination{Limit: &limit, Marker: token}
+	pagination := nativePagination(schema, &types.QueryOptions{Pagination: parsed}, true)
+	if assert.NotNil(t, pagination) {
+		list := &unstructured.UnstructuredList{}
+		list.SetContinue("token")
+		assert.NoError(t, setContinue(pagination, list))
+		assert.True(t, pagination.Native)
+		assert.True(t, pagination.Partial)
+		assert.Equal(t, "token", pagination.Next)
+		assert.False(t, parsed.Native, "the pagination of the options isn't changed")
+
+		data := []map[string]interface{}{{"id": "a"}, {"id": "b"}}
+		assert.Equal(t, data, handler.ApplyPagination(pagination, data))
+	}
+}
@@ -0,0 +1,203 @@
+package proxy
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/rancher/norman/types"
+	"github.com/rancher/norman/types/convert"
+	"github.com/rancher/norman/types/values"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+// selectorProbe is mapped to the internal representation to find where a field is stored
+const selectorProbe = "\x00norman-selector-probe"
+
+// listOptions translates the conditions of opt on fields stored as metadata.name, metadata.namespace or
+// a label into selectors, so Kubernetes filters the list before sending it. The conditions are still
+// applied to the result, so conditions that can't be expressed exactly as a selector are skipped. The
+// returned bool is true if every condition was translated.
+func listOptions(schema *types.Schema, opt *types.QueryOptions) (*metav1.ListOptions, bool) {
+	result := &metav1.ListOptions{}
+	if opt == nil {
+		return result, true
+	}
+
+	exact := true
+	labelSelector := labels.NewSelector()
+	var fieldSelectors []fields.Selector
+
+	for _, condition := range opt.Conditions {
+		if condition.Field == "" {
+			exact = false
+			continue
+		}
+
+		mod := condition.ToCondition().Modifier
+		path := internalPath(schema, condition.Field)
+		switch {
+		case len(path) == 2 && path[0] == "metadata" && (path[1] == "name" || path[1] == "namespace"):
+			if selector := fieldSelector("metadata."+path[1], mod, condition); selector != nil {
+				fieldSelectors = append(fieldSelectors, selector)
+			} else {
+				exact = false
+			}
+		case len(path) == 3 && path[0] == "metadata" && path[1] == "labels":
+			if requirement := labelRequirement(path[2], mod, condition); requirement != nil {
+				labelSelector = labelSelector.Add(*requirement)
+			} else {
+				exact = false
+			}
+		default:
+			exact = false
+		}
+	}
+
+	if !labelSelector.Empty() {
+		result.LabelSelector = labelSelector.String()
+	}
+	if len(fieldSelectors) > 0 {
+		result.FieldSelector = fields.AndSelectors(fieldSelectors...).String()
+	}
+
+	return result, exact
+}
+
+// nativePagination returns a copy of the pagination of opt if it can be done by Kubernetes with limit and
+// continue. That is only the case if the schema enabled it, Kubernetes returns exactly the objects matching
+// the conditions and no sort order was requested, as a page must not be filtered or sorted again afterwards.
+// A marker that isn't a continue token, such as the ID of a page paginated in memory, is left to the
+// pagination in memory.
+func nativePagination(schema *types.Schema, opt *types.QueryOptions, exact bool) *types.Pagination {
+	if !schema.NativePagination || opt == nil || !exact ||
+		len(opt.Sort.Fields) > 0 || opt.Sort.Name != "" || opt.Sort.Order == types.DESC {
+		return nil
+	}
+	pagination := opt.Pagination
+	if pagination == nil || pagination.Limit == nil || *pagination.Limit <= 0 {
+		return nil
+	}
+	if pagination.Marker != "" && !isContinueToken(pagination.Marker) {
+		return nil
+	}
+
+	result := *pagination
+	return &result
+}
+
+// isContinueToken returns true if marker looks like a continue token of Kubernetes, the base64 encoded
+// JSON of the position to continue the list at
+func isContinueToken(marker string) bool {
+	data, err := base64.RawURLEncoding.DecodeString(marker)
+	if err != nil {
+		return false
+	}
+
+	var token struct {
+		Version string `json:"v"`
+	}
+	return json.Unmarshal(data, &token) == nil && token.Version != ""
+}
+
+// setContinue records the continue token of list in pagination, marking it as done natively
+func setContinue(pagination *types.Pagination, list runtime.Object) error {
+	listMeta, err := meta.ListAccessor(list)
+	if err != nil {
+		return err
+	}
+
+	pagination.Native = true
+	pagination.Next = listMeta.GetContinue()
+	pagination.Partial = pagination.Next != "" || pagination.Marker != ""
+	pagination.Total = nil
+	return nil
+}
+
+func fieldSelector(field string, mod types.ModifierType, condition *types.QueryCondition) fields.Selector {
+	switch {
+	case mod == types.ModifierEQ && condition.Value != "":
+		return fields.OneTermEqualSelector(field, condition.Value)
+	case mod == types.ModifierNE:
+		return fields.OneTermNotEqualSelector(field, condition.Value)
+	}
+	return nil
+}
+
+func labelRequirement(key string, mod types.ModifierType, condition *types.QueryCondition) *labels.Requirement {
+	var (
+		op   selection.Operator
+		vals []string
+	)
+
+	switch mod {
+	case types.ModifierEQ:
+		op = selection.Equals
+	case types.ModifierNE:
+		op = selection.NotEquals
+	case types.ModifierIn:
+		op = selection.In
+	case types.ModifierNotIn:
+		op = selection.NotIn
+	default:
+		return nil
+	}
+
+	for value := range condition.Values {
+		// a selector on an empty value won't match objects missing the label, the condition does
+		if value == "" && (op == selection.Equals || op == selection.In) {
+			return nil
+		}
+		vals = append(vals, value)
+	}
+	if len(vals) == 0 {
+		return nil
+	}
+
+	requirement, err := labels.NewRequirement(key, op, vals)
+	if err != nil {
+		return nil
+	}
+	return requirement
+}
+
+// internalPath returns where the API field is stored in the Kubernetes object, following the mappers of schema.
+// The path is nil if the mappers change the value, or fail on an object only holding the field, so the
+// condition is left to the filtering in memory.
+func internalPath(schema *types.Schema, field string) (path []string) {
+	defer func() {
+		if err := recover(); err != nil {
+			logrus.Debugf("Failed to map field %s of %s to a selector: %v", field, schema.ID, err)
+			path = nil
+		}
+	}()
+
+	probe := map[string]interface{}{
+		field: selectorProbe,
+	}
+	if schema.Mapper != nil {
+		if err := schema.Mapper.ToInternal(probe); err != nil {
+			return nil
+		}
+	}
+
+	for _, name := range []string{"name", "namespace"} {
+		if convert.ToString(values.GetValueN(probe, "metadata", name)) == selectorProbe {
+			return []string{"metadata", name}
+		}
+	}
+
+	labelValues, _ := values.GetValueN(probe, "metadata", "labels").(map[string]interface{})
+	for key, value := range labelValues {
+		if convert.ToString(value) == selectorProbe {
+			return []string{"metadata", "labels", key}
+		}
+	}
+
+	return nil
+}
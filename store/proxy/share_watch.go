@@ -5,6 +5,13 @@ import (
 	"github.com/rancher/norman/types"
 )
 
+// WatchBufferSize and WatchSlowConsumerPolicy configure how many events of a shared watch are buffered
+// for each subscriber and what happens once a subscriber falls further behind
+var (
+	WatchBufferSize         = broadcast.DefaultBufferSize
+	WatchSlowConsumerPolicy = broadcast.Disconnect
+)
+
 func (s *Store) shareWatch(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions) (chan map[string]interface{}, error) {
 	client, err := s.clientGetter.UnversionedClient(apiContext, s.Context())
 	if err != nil {
@@ -15,7 +22,10 @@ func (s *Store) shareWatch(apiContext *types.APIContext, schema *types.Schema, o
 	s.Lock()
 	b, ok := s.broadcasters[client]
 	if !ok {
-		b = &broadcast.Broadcaster{}
+		b = &broadcast.Broadcaster{
+			BufferSize: WatchBufferSize,
+			Policy:     WatchSlowConsumerPolicy,
+		}
 		s.broadcasters[client] = b
 	}
 	s.Unlock()
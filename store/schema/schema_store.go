@@ -47,6 +47,9 @@ func (s *Store) modifyForAccessControl(context *types.APIContext, schema types.S
 	if slice.ContainsString(schema.ResourceMethods, http.MethodPut) && schema.CanUpdate(context) == nil {
 		resourceMethods = append(resourceMethods, http.MethodPut)
 	}
+	if slice.ContainsString(schema.ResourceMethods, http.MethodPatch) && schema.CanUpdate(context) == nil {
+		resourceMethods = append(resourceMethods, http.MethodPatch)
+	}
 	if slice.ContainsString(schema.ResourceMethods, http.MethodDelete) && schema.CanDelete(context) == nil {
 		resourceMethods = append(resourceMethods, http.MethodDelete)
 	}
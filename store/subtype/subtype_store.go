@@ -19,6 +19,10 @@ func NewSubTypeStore(subType string, store types.Store) *Store {
 	}
 }
 
+func (p *Store) SupportsDryRun() bool {
+	return types.SupportsDryRun(p.Store)
+}
+
 func (p *Store) Create(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}) (map[string]interface{}, error) {
 	if data != nil {
 		data["kind"] = p.subType
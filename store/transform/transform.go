@@ -25,6 +25,10 @@ func (s *Store) Context() types.StorageContext {
 	return s.Store.Context()
 }
 
+func (s *Store) SupportsDryRun() bool {
+	return types.SupportsDryRun(s.Store)
+}
+
 func (s *Store) ByID(apiContext *types.APIContext, schema *types.Schema, id string) (map[string]interface{}, error) {
 	data, err := s.Store.ByID(apiContext, schema, id)
 	if err != nil {
@@ -55,6 +59,9 @@ func (s *Store) Watch(apiContext *types.APIContext, schema *types.Schema, opt *t
 	}
 
 	return convert.Chan(c, func(data map[string]interface{}) map[string]interface{} {
+		if types.IsWatchControl(data) {
+			return data
+		}
 		item, err := s.Transformer(apiContext, schema, data, opt)
 		if err != nil {
 			return nil
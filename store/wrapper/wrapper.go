@@ -2,8 +2,11 @@ package wrapper
 
 import (
 	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/pkg/metrics"
+	"github.com/rancher/norman/pkg/tracing"
 	"github.com/rancher/norman/types"
 	"github.com/rancher/norman/types/convert"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 func Wrap(store types.Store) types.Store {
@@ -12,7 +15,7 @@ func Wrap(store types.Store) types.Store {
 	}
 
 	return &StoreWrapper{
-		store: store,
+		store: metrics.InstrumentStore("api", store),
 	}
 }
 
@@ -24,7 +27,14 @@ func (s *StoreWrapper) Context() types.StorageContext {
 	return s.store.Context()
 }
 
-func (s *StoreWrapper) ByID(apiContext *types.APIContext, schema *types.Schema, id string) (map[string]interface{}, error) {
+func (s *StoreWrapper) SupportsDryRun() bool {
+	return types.SupportsDryRun(s.store)
+}
+
+func (s *StoreWrapper) ByID(apiContext *types.APIContext, schema *types.Schema, id string) (_ map[string]interface{}, err error) {
+	apiContext, end := startSpan(apiContext, schema, "byID")
+	defer func() { end(err) }()
+
 	data, err := s.store.ByID(apiContext, schema, id)
 	if err != nil {
 		return nil, err
@@ -35,7 +45,10 @@ func (s *StoreWrapper) ByID(apiContext *types.APIContext, schema *types.Schema,
 	}, schema, data), nil
 }
 
-func (s *StoreWrapper) List(apiContext *types.APIContext, schema *types.Schema, opts *types.QueryOptions) ([]map[string]interface{}, error) {
+func (s *StoreWrapper) List(apiContext *types.APIContext, schema *types.Schema, opts *types.QueryOptions) (_ []map[string]interface{}, err error) {
+	apiContext, end := startSpan(apiContext, schema, "list")
+	defer func() { end(err) }()
+
 	opts.Conditions = append(opts.Conditions, apiContext.SubContextAttributeProvider.Query(apiContext, schema)...)
 	data, err := s.store.List(apiContext, schema, opts)
 	if err != nil {
@@ -45,20 +58,32 @@ func (s *StoreWrapper) List(apiContext *types.APIContext, schema *types.Schema,
 	return apiContext.FilterList(opts, schema, data), nil
 }
 
-func (s *StoreWrapper) Watch(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions) (chan map[string]interface{}, error) {
+func (s *StoreWrapper) Watch(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions) (_ chan map[string]interface{}, err error) {
+	// the watches of a subscription share apiContext concurrently, so the span isn't put on its request
+	if apiContext.Request != nil {
+		_, span := tracing.Start(apiContext.Request.Context(), "store.watch", attribute.String("norman.schema", schema.ID))
+		defer func() { tracing.End(span, err) }()
+	}
+
 	c, err := s.store.Watch(apiContext, schema, opt)
 	if err != nil || c == nil {
 		return nil, err
 	}
 
 	return convert.Chan(c, func(data map[string]interface{}) map[string]interface{} {
+		if types.IsWatchControl(data) {
+			return data
+		}
 		return apiContext.FilterObject(&types.QueryOptions{
 			Conditions: apiContext.SubContextAttributeProvider.Query(apiContext, schema),
 		}, schema, data)
 	}), nil
 }
 
-func (s *StoreWrapper) Create(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}) (map[string]interface{}, error) {
+func (s *StoreWrapper) Create(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}) (_ map[string]interface{}, err error) {
+	apiContext, end := startSpan(apiContext, schema, "create")
+	defer func() { end(err) }()
+
 	for key, value := range apiContext.SubContextAttributeProvider.Create(apiContext, schema) {
 		if data == nil {
 			data = map[string]interface{}{}
@@ -66,7 +91,7 @@ func (s *StoreWrapper) Create(apiContext *types.APIContext, schema *types.Schema
 		data[key] = value
 	}
 
-	data, err := s.store.Create(apiContext, schema, data)
+	data, err = s.store.Create(apiContext, schema, data)
 	if err != nil {
 		return nil, err
 	}
@@ -74,8 +99,11 @@ func (s *StoreWrapper) Create(apiContext *types.APIContext, schema *types.Schema
 	return data, nil
 }
 
-func (s *StoreWrapper) Update(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}, id string) (map[string]interface{}, error) {
-	err := validateGet(apiContext, schema, id)
+func (s *StoreWrapper) Update(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}, id string) (_ map[string]interface{}, err error) {
+	apiContext, end := startSpan(apiContext, schema, "update")
+	defer func() { end(err) }()
+
+	err = validateGet(apiContext, schema, id)
 	if err != nil {
 		return nil, err
 	}
@@ -90,7 +118,10 @@ func (s *StoreWrapper) Update(apiContext *types.APIContext, schema *types.Schema
 	}, schema, data), nil
 }
 
-func (s *StoreWrapper) Delete(apiContext *types.APIContext, schema *types.Schema, id string) (map[string]interface{}, error) {
+func (s *StoreWrapper) Delete(apiContext *types.APIContext, schema *types.Schema, id string) (_ map[string]interface{}, err error) {
+	apiContext, end := startSpan(apiContext, schema, "delete")
+	defer func() { end(err) }()
+
 	if err := validateGet(apiContext, schema, id); err != nil {
 		return nil, err
 	}
@@ -98,6 +129,32 @@ func (s *StoreWrapper) Delete(apiContext *types.APIContext, schema *types.Schema
 	return s.store.Delete(apiContext, schema, id)
 }
 
+// startSpan starts the span of a store call. It returns a shallow copy of apiContext whose request carries
+// the span, so the layers below start their spans as its children while concurrent calls sharing
+// apiContext keep its request. The returned func ends the span, keeping the pagination a store set on
+// the copy.
+func startSpan(apiContext *types.APIContext, schema *types.Schema, operation string) (*types.APIContext, func(err error)) {
+	if apiContext.Request == nil {
+		return apiContext, func(error) {}
+	}
+
+	ctx, span := tracing.Start(apiContext.Request.Context(), "store."+operation, attribute.String("norman.schema", schema.ID))
+	if !span.IsRecording() {
+		return apiContext, func(err error) { tracing.End(span, err) }
+	}
+
+	pagination := apiContext.Pagination
+	traced := *apiContext
+	traced.Request = apiContext.Request.WithContext(ctx)
+	return &traced, func(err error) {
+		// stores paginating natively replace the pagination of the context
+		if traced.Pagination != pagination {
+			apiContext.Pagination = traced.Pagination
+		}
+		tracing.End(span, err)
+	}
+}
+
 func validateGet(apiContext *types.APIContext, schema *types.Schema, id string) error {
 	store := schema.Store
 	if store == nil {
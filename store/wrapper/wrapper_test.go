@@ -1,6 +1,9 @@
 package wrapper
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/rancher/norman/api/handler"
@@ -8,6 +11,10 @@ import (
 	"github.com/rancher/norman/store/empty"
 	"github.com/rancher/norman/types"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type testStore struct {
@@ -50,3 +57,45 @@ func TestWrap(t *testing.T) {
 	assert.Equal(t, int64(1), *apiContext.Pagination.Limit)
 
 }
+
+// spanStore records the span it is called with and changes the context like stores paginating do
+type spanStore struct {
+	empty.Store
+	span trace.SpanContext
+	// shared is the context the store is called with, and sharedRequest its request during the call
+	shared        *types.APIContext
+	sharedRequest *http.Request
+}
+
+func (s *spanStore) List(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions) ([]map[string]interface{}, error) {
+	s.span = trace.SpanContextFromContext(apiContext.Request.Context())
+	s.sharedRequest = s.shared.Request
+	apiContext.Pagination = &types.Pagination{Partial: true}
+	return nil, nil
+}
+
+func TestWrapTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	ctx, root := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)).Tracer("test").Start(context.Background(), "request")
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/hobbits", nil).WithContext(ctx)
+	apiContext := &types.APIContext{
+		Request:                     req,
+		SubContextAttributeProvider: &parse.DefaultSubContextAttributeProvider{},
+		QueryFilter:                 handler.QueryFilter,
+	}
+
+	store := &spanStore{shared: apiContext}
+	_, err := Wrap(store).List(apiContext, &types.Schema{ID: "hobbit"}, &types.QueryOptions{})
+	require.NoError(t, err)
+	root.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "store.list", spans[0].Name)
+	assert.Equal(t, spans[0].SpanContext.SpanID(), store.span.SpanID(), "the store runs in the span of the call")
+
+	assert.Same(t, req, store.sharedRequest, "the request of the shared context isn't changed")
+	assert.Same(t, req, apiContext.Request)
+	assert.True(t, apiContext.Pagination.Partial, "changes of the store to the context are kept")
+}
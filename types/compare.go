@@ -0,0 +1,100 @@
+package types
+
+import (
+	"cmp"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/rancher/norman/types/convert"
+)
+
+var errEmpty = errors.New("empty value")
+
+// CompareFieldValues compares two values of field according to the field type, returning -1, 0 or +1.
+// Empty values and values that can not be converted to the field type sort before all others.
+func CompareFieldValues(field Field, left, right interface{}) int {
+	switch field.Type {
+	case "int":
+		return compareConverted(left, right, convert.ToNumber)
+	case "float":
+		return compareConverted(left, right, convert.ToFloat)
+	case "date":
+		return compareConverted(left, right, toTime)
+	case "boolean":
+		return compareConverted(left, right, toBoolNumber)
+	case "enum":
+		if result := compareConverted(left, right, func(value interface{}) (int, error) {
+			return optionIndex(field.Options, value), nil
+		}); result != 0 {
+			return result
+		}
+	}
+
+	return strings.Compare(convert.ToString(left), convert.ToString(right))
+}
+
+// ValidateCompareValue returns an error if value can't be converted to the type of field, so values of the
+// field can't be ordered against it
+func ValidateCompareValue(field Field, value string) error {
+	var err error
+	switch field.Type {
+	case "int":
+		_, err = convertForCompare(value, convert.ToNumber)
+	case "float":
+		_, err = convertForCompare(value, convert.ToFloat)
+	case "date":
+		_, err = convertForCompare(value, toTime)
+	}
+	return err
+}
+
+func compareConverted[T cmp.Ordered](left, right interface{}, f func(interface{}) (T, error)) int {
+	leftValue, leftErr := convertForCompare(left, f)
+	rightValue, rightErr := convertForCompare(right, f)
+
+	switch {
+	case leftErr != nil && rightErr != nil:
+		return strings.Compare(convert.ToString(left), convert.ToString(right))
+	case leftErr != nil:
+		return -1
+	case rightErr != nil:
+		return 1
+	}
+
+	return cmp.Compare(leftValue, rightValue)
+}
+
+func convertForCompare[T cmp.Ordered](value interface{}, f func(interface{}) (T, error)) (T, error) {
+	if convert.ToString(value) == "" {
+		var zero T
+		return zero, errEmpty
+	}
+	return f(value)
+}
+
+func toTime(value interface{}) (int64, error) {
+	t, err := time.Parse(time.RFC3339, convert.ToString(value))
+	if err != nil {
+		return 0, err
+	}
+	return t.UnixNano(), nil
+}
+
+func toBoolNumber(value interface{}) (int, error) {
+	if convert.ToBool(value) {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// optionIndex orders values by their position in the options, unknown values sort after all options
+func optionIndex(options []string, value interface{}) int {
+	str := convert.ToString(value)
+	for i, option := range options {
+		if option == str {
+			return i
+		}
+	}
+	return len(options)
+}
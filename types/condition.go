@@ -1,6 +1,10 @@
 package types
 
 import (
+	"sort"
+	"strconv"
+	"strings"
+
 	"github.com/rancher/norman/types/convert"
 )
 
@@ -11,8 +15,15 @@ var (
 	CondNotNull = QueryConditionType{ModifierNotNull, 0}
 	CondIn      = QueryConditionType{ModifierIn, -1}
 	CondNotIn   = QueryConditionType{ModifierNotIn, -1}
+	CondGT      = QueryConditionType{ModifierGT, 1}
+	CondGTE     = QueryConditionType{ModifierGTE, 1}
+	CondLT      = QueryConditionType{ModifierLT, 1}
+	CondLTE     = QueryConditionType{ModifierLTE, 1}
+	CondPrefix  = QueryConditionType{ModifierPrefix, 1}
+	CondLike    = QueryConditionType{ModifierLike, 1}
 	CondOr      = QueryConditionType{ModifierType("or"), 1}
 	CondAnd     = QueryConditionType{ModifierType("and"), 1}
+	CondSearch  = QueryConditionType{ModifierType("search"), 1}
 
 	mods = map[ModifierType]QueryConditionType{
 		CondEQ.Name:      CondEQ,
@@ -21,6 +32,12 @@ var (
 		CondNotNull.Name: CondNotNull,
 		CondIn.Name:      CondIn,
 		CondNotIn.Name:   CondNotIn,
+		CondGT.Name:      CondGT,
+		CondGTE.Name:     CondGTE,
+		CondLT.Name:      CondLT,
+		CondLTE.Name:     CondLTE,
+		CondPrefix.Name:  CondPrefix,
+		CondLike.Name:    CondLike,
 		CondOr.Name:      CondOr,
 		CondAnd.Name:     CondAnd,
 	}
@@ -37,6 +54,7 @@ type QueryCondition struct {
 	Values        map[string]bool
 	conditionType QueryConditionType
 	left, right   *QueryCondition
+	searchFields  []string
 }
 
 func (q *QueryCondition) Valid(schema *Schema, data map[string]interface{}) bool {
@@ -63,11 +81,80 @@ func (q *QueryCondition) Valid(schema *Schema, data map[string]interface{}) bool
 		return convert.ToString(valueOrDefault(schema, data, q)) != ""
 	case CondNull:
 		return convert.ToString(valueOrDefault(schema, data, q)) == ""
+	case CondGT:
+		return q.compare(schema, data, func(result int) bool { return result > 0 })
+	case CondGTE:
+		return q.compare(schema, data, func(result int) bool { return result >= 0 })
+	case CondLT:
+		return q.compare(schema, data, func(result int) bool { return result < 0 })
+	case CondLTE:
+		return q.compare(schema, data, func(result int) bool { return result <= 0 })
+	case CondPrefix:
+		return strings.HasPrefix(convert.ToString(valueOrDefault(schema, data, q)), q.Value)
+	case CondLike:
+		return strings.Contains(strings.ToLower(convert.ToString(valueOrDefault(schema, data, q))), strings.ToLower(q.Value))
+	case CondSearch:
+		text := strings.ToLower(q.Value)
+		for _, field := range q.searchFields {
+			if searchMatches(data[field], text) {
+				return true
+			}
+		}
+		return false
 	}
 
 	return false
 }
 
+// compare orders the value of data against the condition value by the field type. Empty values never match,
+// and neither does anything if the condition value can't be converted to the field type.
+func (q *QueryCondition) compare(schema *Schema, data map[string]interface{}, matches func(int) bool) bool {
+	field := schema.ResourceFields[q.Field]
+	value := valueOrDefault(schema, data, q)
+	if convert.ToString(value) == "" || ValidateCompareValue(field, q.Value) != nil {
+		return false
+	}
+	return matches(CompareFieldValues(field, value, q.Value))
+}
+
+// searchMatches returns true if value contains the lower case text, ignoring case. The keys and values of
+// maps such as labels and the items of slices are searched individually.
+func searchMatches(value interface{}, text string) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case map[string]interface{}:
+		for key, item := range v {
+			if searchMatches(key, text) || searchMatches(item, text) {
+				return true
+			}
+		}
+		return false
+	case map[string]string:
+		for key, item := range v {
+			if searchMatches(key, text) || searchMatches(item, text) {
+				return true
+			}
+		}
+		return false
+	case []interface{}:
+		for _, item := range v {
+			if searchMatches(item, text) {
+				return true
+			}
+		}
+		return false
+	case []string:
+		for _, item := range v {
+			if searchMatches(item, text) {
+				return true
+			}
+		}
+		return false
+	}
+	return strings.Contains(strings.ToLower(convert.ToString(value)), text)
+}
+
 func valueOrDefault(schema *Schema, data map[string]interface{}, q *QueryCondition) interface{} {
 	value := data[q.Field]
 	if value == nil {
@@ -81,6 +168,10 @@ func (q *QueryCondition) ToCondition() Condition {
 	cond := Condition{
 		Modifier: q.conditionType.Name,
 	}
+	if q.conditionType == CondAnd || q.conditionType == CondOr {
+		cond.Value = q.String()
+		return cond
+	}
 	switch q.conditionType.Args {
 	case 1:
 		cond.Value = q.Value
@@ -104,6 +195,79 @@ func EQ(key, value string) *QueryCondition {
 	return NewConditionFromString(key, ModifierEQ, value)
 }
 
+// And matches data matching both left and right
+func And(left, right *QueryCondition) *QueryCondition {
+	return &QueryCondition{
+		Values:        map[string]bool{},
+		conditionType: CondAnd,
+		left:          left,
+		right:         right,
+	}
+}
+
+// Or matches data matching either left or right
+func Or(left, right *QueryCondition) *QueryCondition {
+	return &QueryCondition{
+		Values:        map[string]bool{},
+		conditionType: CondOr,
+		left:          left,
+		right:         right,
+	}
+}
+
+// String renders the condition in the filter expression syntax, such as
+// state=active OR (state=updating AND name_prefix=x)
+func (q *QueryCondition) String() string {
+	switch q.conditionType {
+	case CondAnd, CondOr:
+		return q.left.group() + " " + strings.ToUpper(string(q.conditionType.Name)) + " " + q.right.group()
+	}
+
+	name := q.Field
+	if q.conditionType != CondEQ && q.conditionType != CondSearch {
+		name += "_" + string(q.conditionType.Name)
+	}
+
+	switch q.conditionType.Args {
+	case 0:
+		return name
+	case -1:
+		var values []string
+		for value := range q.Values {
+			values = append(values, quoteValue(value))
+		}
+		sort.Strings(values)
+		return name + "=" + strings.Join(values, ",")
+	}
+	return name + "=" + quoteValue(q.Value)
+}
+
+func (q *QueryCondition) group() string {
+	if q.conditionType == CondAnd || q.conditionType == CondOr {
+		return "(" + q.String() + ")"
+	}
+	return q.String()
+}
+
+func quoteValue(value string) string {
+	if value == "" || strings.ContainsAny(value, " \t\r\n()\",") {
+		return strconv.Quote(value)
+	}
+	return value
+}
+
+// NewSearchCondition matches data containing value, ignoring case, in any of the fields. field is the name
+// the condition is reported under in the collection filters.
+func NewSearchCondition(field, value string, fields []string) *QueryCondition {
+	return &QueryCondition{
+		Field:         field,
+		Value:         value,
+		Values:        map[string]bool{},
+		conditionType: CondSearch,
+		searchFields:  fields,
+	}
+}
+
 func NewConditionFromString(field string, mod ModifierType, values ...string) *QueryCondition {
 	q := &QueryCondition{
 		Field:         field,
@@ -0,0 +1,92 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConditionModifiers(t *testing.T) {
+	schema := &Schema{
+		ResourceFields: map[string]Field{
+			"name":    {Type: "string"},
+			"memory":  {Type: "int"},
+			"created": {Type: "date"},
+		},
+	}
+
+	data := map[string]interface{}{
+		"name":    "prod-cluster",
+		"memory":  int64(10),
+		"created": "2024-01-02T00:00:00Z",
+	}
+
+	tests := []struct {
+		field    string
+		mod      ModifierType
+		value    string
+		expected bool
+	}{
+		{field: "memory", mod: ModifierGT, value: "9", expected: true},
+		{field: "memory", mod: ModifierGT, value: "10", expected: false},
+		{field: "memory", mod: ModifierGTE, value: "10", expected: true},
+		{field: "memory", mod: ModifierLT, value: "100", expected: true},
+		{field: "memory", mod: ModifierLTE, value: "9", expected: false},
+		{field: "created", mod: ModifierGT, value: "2024-01-01T23:00:00-02:00", expected: false},
+		{field: "created", mod: ModifierLT, value: "2024-01-02T00:00:01Z", expected: true},
+		{field: "name", mod: ModifierPrefix, value: "prod", expected: true},
+		{field: "name", mod: ModifierPrefix, value: "cluster", expected: false},
+		{field: "name", mod: ModifierLike, value: "CLUSTER", expected: true},
+		{field: "missing", mod: ModifierLT, value: "10", expected: false},
+		{field: "memory", mod: ModifierGT, value: "abc", expected: false},
+		{field: "memory", mod: ModifierLT, value: "", expected: false},
+		{field: "created", mod: ModifierGT, value: "2024-01-01", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field+"_"+string(tt.mod)+"="+tt.value, func(t *testing.T) {
+			assert.True(t, ValidMod(tt.mod))
+			assert.Equal(t, tt.expected, NewConditionFromString(tt.field, tt.mod, tt.value).Valid(schema, data))
+		})
+	}
+}
+
+func TestSearchCondition(t *testing.T) {
+	schema := &Schema{
+		ResourceFields: map[string]Field{
+			"name":        {Type: "string"},
+			"description": {Type: "string"},
+			"labels":      {Type: "map[string]"},
+			"secret":      {Type: "string"},
+		},
+	}
+
+	data := map[string]interface{}{
+		"name":        "cluster-a",
+		"description": "Production workloads",
+		"labels":      map[string]interface{}{"env": "staging", "tier-prod": "true"},
+		"secret":      "hunter2",
+	}
+
+	fields := []string{"name", "description", "labels"}
+	assert.True(t, NewSearchCondition("q", "CLUSTER", fields).Valid(schema, data))
+	assert.True(t, NewSearchCondition("q", "production", fields).Valid(schema, data))
+	assert.True(t, NewSearchCondition("q", "stag", fields).Valid(schema, data))
+	assert.True(t, NewSearchCondition("q", "tier", fields).Valid(schema, data))
+	assert.False(t, NewSearchCondition("q", "hunter", fields).Valid(schema, data))
+	assert.Equal(t, Condition{Modifier: "search", Value: "stag"}, NewSearchCondition("q", "stag", fields).ToCondition())
+}
+
+func TestConditionString(t *testing.T) {
+	condition := Or(
+		NewConditionFromString("state", ModifierEQ, "active"),
+		And(
+			NewConditionFromString("state", ModifierIn, "updating", "removing"),
+			NewConditionFromString("name", ModifierPrefix, "prod cluster"),
+		),
+	)
+
+	assert.Equal(t, `state=active OR (state_in=removing,updating AND name_prefix="prod cluster")`, condition.String())
+	assert.Equal(t, Condition{Modifier: "or", Value: condition.String()}, condition.ToCondition())
+	assert.Equal(t, "name_notnull", NewConditionFromString("name", ModifierNotNull).String())
+}
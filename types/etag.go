@@ -0,0 +1,34 @@
+package types
+
+import "strings"
+
+// ETag returns the entity tag of data returned by a store, derived from its resource version. It's empty
+// if the store doesn't version its data.
+func ETag(data map[string]interface{}) string {
+	version, _ := data[ResourceFieldResourceVersion].(string)
+	if version == "" {
+		return ""
+	}
+	return `"` + version + `"`
+}
+
+// ETagMatches returns true if the If-Match or If-None-Match header value lists etag or "*"
+func ETagMatches(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// ETagVersion returns the resource version of the entity tag in an If-Match header value, or an empty
+// string if the header doesn't name exactly one version.
+func ETagVersion(header string) string {
+	tag := strings.TrimSpace(header)
+	if len(tag) < 2 || strings.Contains(tag, ",") || !strings.HasPrefix(tag, `"`) || !strings.HasSuffix(tag, `"`) {
+		return ""
+	}
+	return tag[1 : len(tag)-1]
+}
@@ -104,15 +104,17 @@ func (s *Schemas) setupFilters(schema *Schema) {
 		case "enum":
 			mods = []ModifierType{ModifierEQ, ModifierNE, ModifierIn, ModifierNotIn}
 		case "date":
-			fallthrough
+			mods = []ModifierType{ModifierEQ, ModifierNE, ModifierIn, ModifierNotIn, ModifierGT, ModifierGTE, ModifierLT, ModifierLTE}
 		case "dnsLabel":
 			fallthrough
 		case "hostname":
 			fallthrough
 		case "string":
-			mods = []ModifierType{ModifierEQ, ModifierNE, ModifierIn, ModifierNotIn}
+			mods = []ModifierType{ModifierEQ, ModifierNE, ModifierIn, ModifierNotIn, ModifierPrefix, ModifierLike}
 		case "int":
-			mods = []ModifierType{ModifierEQ, ModifierNE, ModifierIn, ModifierNotIn}
+			fallthrough
+		case "float":
+			mods = []ModifierType{ModifierEQ, ModifierNE, ModifierIn, ModifierNotIn, ModifierGT, ModifierGTE, ModifierLT, ModifierLTE}
 		case "boolean":
 			mods = []ModifierType{ModifierEQ, ModifierNE}
 		default:
@@ -222,7 +224,7 @@ func k8sObject(field reflect.StructField) bool {
 func (s *Schemas) readFields(schema *Schema, t reflect.Type) error {
 	if t == resourceType {
 		schema.CollectionMethods = []string{"GET", "POST"}
-		schema.ResourceMethods = []string{"GET", "PUT", "DELETE"}
+		schema.ResourceMethods = []string{"GET", "PUT", "PATCH", "DELETE"}
 	}
 
 	hasType := false
@@ -309,6 +311,10 @@ func (s *Schemas) readFields(schema *Schema, t reflect.Type) error {
 			return err
 		}
 
+		if hasTag(&field, "search") && !slice.ContainsString(schema.SearchFields, fieldName) {
+			schema.SearchFields = append(schema.SearchFields, fieldName)
+		}
+
 		if schemaField.Type == "" {
 			inferedType, err := s.determineSchemaType(&schema.Version, fieldType)
 			if err != nil {
@@ -342,7 +348,7 @@ func (s *Schemas) readFields(schema *Schema, t reflect.Type) error {
 
 	if hasType && hasMeta {
 		schema.CollectionMethods = []string{"GET", "POST"}
-		schema.ResourceMethods = []string{"GET", "PUT", "DELETE"}
+		schema.ResourceMethods = []string{"GET", "PUT", "PATCH", "DELETE"}
 	}
 
 	return nil
@@ -395,6 +401,8 @@ func applyTag(structField *reflect.StructField, field *Field) error {
 			field.InvalidChars = value
 		case "pointer":
 			field.Pointer = true
+		case "search":
+			// added to the SearchFields of the schema by readFields
 		default:
 			return fmt.Errorf("invalid tag %s on field %s", key, structField.Name)
 		}
@@ -407,6 +415,15 @@ func applyTag(structField *reflect.StructField, field *Field) error {
 	return nil
 }
 
+func hasTag(structField *reflect.StructField, tag string) bool {
+	for _, part := range strings.Split(structField.Tag.Get("norman"), ",") {
+		if key, _ := getKeyValue(part); key == tag {
+			return true
+		}
+	}
+	return false
+}
+
 func toInt(value string, structField *reflect.StructField) (*int64, error) {
 	i, err := strconv.ParseInt(value, 10, 64)
 	if err != nil {
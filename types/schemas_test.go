@@ -74,3 +74,22 @@ func TestSchemas(t *testing.T) {
 
 	assert.ElementsMatch(t, expected, actual)
 }
+
+func TestSearchTag(t *testing.T) {
+	type Hobbit struct {
+		Name        string            `json:"name" norman:"search"`
+		Description string            `json:"description" norman:"search,nullable"`
+		Labels      map[string]string `json:"labels" norman:"search"`
+		Breakfasts  int               `json:"breakfasts"`
+	}
+
+	version := APIVersion{
+		Group:   "meta.cattle.io",
+		Version: "v1",
+		Path:    "/shire",
+	}
+
+	schema := NewSchemas().MustImport(&version, Hobbit{}).Schema(&version, "hobbit")
+	assert.Equal(t, []string{"name", "description", "labels"}, schema.SearchFields)
+	assert.True(t, schema.ResourceFields["description"].Nullable)
+}
@@ -196,6 +196,8 @@ type QueryOptions struct {
 	Options    map[string]string
 	// Set namespaces to an empty array will result in an empty response
 	Namespaces []string
+	// ResourceVersion resumes a watch after the given version instead of starting with the current state
+	ResourceVersion string
 }
 
 type ReferenceValidator interface {
@@ -223,6 +225,13 @@ type URLBuilder interface {
 	ActionLinkByID(schema *Schema, id string, action string) string
 }
 
+// MultiSortURLBuilder is implemented by URL builders that link to collections sorted by several fields
+type MultiSortURLBuilder interface {
+	// SortFields links to the current collection sorted by fields in turn, a field prefixed with "-"
+	// sorts descending
+	SortFields(fields ...string) string
+}
+
 type StorageContext string
 
 var DefaultStorageContext StorageContext
@@ -236,3 +245,16 @@ type Store interface {
 	Delete(apiContext *APIContext, schema *Schema, id string) (map[string]interface{}, error)
 	Watch(apiContext *APIContext, schema *Schema, opt *QueryOptions) (chan map[string]interface{}, error)
 }
+
+// DryRunStore is implemented by stores that handle the dryRun option on Create, Update and Delete
+// themselves, validating the change without persisting it. Stores wrapping another store implement it
+// to forward the answer of the wrapped store.
+type DryRunStore interface {
+	SupportsDryRun() bool
+}
+
+// SupportsDryRun returns true if store can be called for a dry run
+func SupportsDryRun(store Store) bool {
+	dryRunStore, ok := store.(DryRunStore)
+	return ok && dryRunStore.SupportsDryRun()
+}
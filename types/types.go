@@ -2,6 +2,14 @@ package types
 
 const (
 	ResourceFieldID = "id"
+	// ResourceFieldResourceVersion holds the version of data returned by a store. It isn't part of the
+	// API representation, but is the source of ETags and of versions to resume watches from.
+	ResourceFieldResourceVersion = ".resourceVersion"
+
+	// JSONPatchType is the content type of a PATCH request body holding RFC 6902 JSON patch operations
+	JSONPatchType = "application/json-patch+json"
+	// MergePatchType is the content type of a PATCH request body holding an RFC 7386 JSON merge patch
+	MergePatchType = "application/merge-patch+json"
 )
 
 type Collection struct {
@@ -12,6 +20,7 @@ type Collection struct {
 	Pagination   *Pagination            `json:"pagination,omitempty"`
 	Sort         *Sort                  `json:"sort,omitempty"`
 	Filters      map[string][]Condition `json:"filters,omitempty"`
+	Fields       []string               `json:"fields,omitempty"`
 	ResourceType string                 `json:"resourceType"`
 }
 
@@ -30,10 +39,17 @@ type SortOrder string
 type Sort struct {
 	Name    string            `json:"name,omitempty"`
 	Order   SortOrder         `json:"order,omitempty"`
+	Fields  []SortField       `json:"fields,omitempty"`
 	Reverse string            `json:"reverse,omitempty"`
 	Links   map[string]string `json:"links,omitempty"`
 }
 
+// SortField is a single key of a multi-key sort, Order is the effective direction of the key
+type SortField struct {
+	Name  string    `json:"name"`
+	Order SortOrder `json:"order,omitempty"`
+}
+
 var (
 	ModifierEQ      ModifierType = "eq"
 	ModifierNE      ModifierType = "ne"
@@ -41,6 +57,12 @@ var (
 	ModifierNotNull ModifierType = "notnull"
 	ModifierIn      ModifierType = "in"
 	ModifierNotIn   ModifierType = "notin"
+	ModifierGT      ModifierType = "gt"
+	ModifierGTE     ModifierType = "gte"
+	ModifierLT      ModifierType = "lt"
+	ModifierLTE     ModifierType = "lte"
+	ModifierPrefix  ModifierType = "prefix"
+	ModifierLike    ModifierType = "like"
 )
 
 type ModifierType string
@@ -59,6 +81,9 @@ type Pagination struct {
 	Limit    *int64 `json:"limit,omitempty"`
 	Total    *int64 `json:"total,omitempty"`
 	Partial  bool   `json:"partial,omitempty"`
+	// Native is set by stores that paginate natively. Marker and Next are then opaque tokens of the
+	// store and the list is not paginated again in memory.
+	Native bool `json:"-"`
 }
 
 type Resource struct {
@@ -66,6 +91,12 @@ type Resource struct {
 	Type    string            `json:"type,omitempty"`
 	Links   map[string]string `json:"links"`
 	Actions map[string]string `json:"actions"`
+	// ETag is the entity tag the resource was served with, sent as If-Match when updating it
+	ETag string `json:"-"`
+}
+
+func (r *Resource) SetETag(etag string) {
+	r.ETag = etag
 }
 
 type NamedResource struct {
@@ -112,10 +143,14 @@ type Schema struct {
 	CollectionFields     map[string]Field  `json:"collectionFields,omitempty"`
 	CollectionActions    map[string]Action `json:"collectionActions,omitempty"`
 	CollectionFilters    map[string]Filter `json:"collectionFilters,omitempty"`
+	SearchFields         []string          `json:"searchFields,omitempty"`
 	DynamicSchemaVersion string            `json:"dynamicSchemaVersion,omitempty"`
 	Scope                TypeScope         `json:"-"`
 	Enabled              func() bool       `json:"-"`
 	Status               bool              `json:"-"`
+	CRDValidation        bool              `json:"-"`
+	NativePagination     bool              `json:"-"`
+	Bulk                 bool              `json:"-"`
 
 	InternalSchema      *Schema             `json:"-"`
 	Mapper              Mapper              `json:"-"`
@@ -157,12 +192,40 @@ type Field struct {
 type Action struct {
 	Input  string `json:"input,omitempty"`
 	Output string `json:"output,omitempty"`
+	// Async runs the action in the background. The request is answered with the operation tracking it,
+	// whose result is the output of the action once it finished.
+	Async bool `json:"async,omitempty"`
 }
 
 type Filter struct {
 	Modifiers []ModifierType `json:"modifiers,omitempty"`
 }
 
+// Summary is the response of a collection request with the summary parameter. Counts holds the number
+// of resources per value of each requested field.
+type Summary struct {
+	Type         string                      `json:"type,omitempty"`
+	ResourceType string                      `json:"resourceType"`
+	Total        int64                       `json:"total"`
+	Counts       map[string]map[string]int64 `json:"counts,omitempty"`
+}
+
+// Operation is the response of an asynchronous action, Status is one of running, succeeded or failed
+type Operation struct {
+	Resource
+	Action       string                 `json:"action"`
+	ResourceType string                 `json:"resourceType"`
+	ResourceID   string                 `json:"resourceId,omitempty"`
+	Status       string                 `json:"status"`
+	Progress     int64                  `json:"progress"`
+	Message      string                 `json:"message,omitempty"`
+	Result       interface{}            `json:"result,omitempty"`
+	ResultType   string                 `json:"resultType,omitempty"`
+	Error        map[string]interface{} `json:"error,omitempty"`
+	Created      string                 `json:"created,omitempty"`
+	Finished     string                 `json:"finished,omitempty"`
+}
+
 type ListOpts struct {
 	Filters map[string]interface{}
 }
@@ -0,0 +1,14 @@
+package types
+
+// Keys of watch events that are not part of the object. Bookmark and resync events carry no object at all
+// and are passed through by stores and filters unchanged. Their version is in ResourceFieldResourceVersion.
+const (
+	WatchRemoved  = ".removed"
+	WatchBookmark = ".bookmark"
+	WatchResync   = ".resync"
+)
+
+// IsWatchControl returns true if the watch event data is a bookmark or resync event instead of an object
+func IsWatchControl(data map[string]interface{}) bool {
+	return data[WatchBookmark] == true || data[WatchResync] == true
+}
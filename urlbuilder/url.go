@@ -120,6 +120,8 @@ func (u *urlBuilder) Marker(marker string) string {
 	return u.requestURL + "?" + newValues.Encode()
 }
 
+// ReverseSort links to the current collection sorted in the opposite direction. Multi-key sorts
+// and sorts with per-field directions reverse every field of the sort parameter.
 func (u *urlBuilder) ReverseSort(order types.SortOrder) string {
 	newValues := url.Values{}
 	for k, v := range u.query {
@@ -127,7 +129,19 @@ func (u *urlBuilder) ReverseSort(order types.SortOrder) string {
 	}
 	newValues.Del("order")
 	newValues.Del("marker")
-	if order == types.ASC {
+
+	sortFields := strings.Split(newValues.Get("sort"), ",")
+	if len(sortFields) > 1 || strings.HasPrefix(sortFields[0], "-") {
+		for i, field := range sortFields {
+			descending := strings.HasPrefix(field, "-") != (order == types.DESC)
+			field = strings.TrimPrefix(field, "-")
+			if !descending {
+				field = "-" + field
+			}
+			sortFields[i] = field
+		}
+		newValues.Set("sort", strings.Join(sortFields, ","))
+	} else if order == types.ASC {
 		newValues.Add("order", string(types.DESC))
 	} else {
 		newValues.Add("order", string(types.ASC))
@@ -144,14 +158,21 @@ func (u *urlBuilder) RelativeToRoot(path string) string {
 	return u.responseURLBase + path
 }
 
+// Sort links to the current collection sorted by field
 func (u *urlBuilder) Sort(field string) string {
+	return u.SortFields(field)
+}
+
+// SortFields links to the current collection sorted by fields in turn, a field prefixed with "-" sorts
+// descending
+func (u *urlBuilder) SortFields(fields ...string) string {
 	newValues := url.Values{}
 	for k, v := range u.query {
 		newValues[k] = v
 	}
 	newValues.Del("order")
 	newValues.Del("marker")
-	newValues.Set("sort", field)
+	newValues.Set("sort", strings.Join(fields, ","))
 	return u.requestURL + "?" + newValues.Encode()
 }
 
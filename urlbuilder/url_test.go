@@ -0,0 +1,58 @@
+package urlbuilder
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/rancher/norman/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortLinks(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		order   types.SortOrder
+		reverse string
+	}{
+		{
+			name:    "single field",
+			query:   "sort=name",
+			order:   types.ASC,
+			reverse: "order=desc&sort=name",
+		},
+		{
+			name:    "multiple fields",
+			query:   "sort=name,-created",
+			order:   types.ASC,
+			reverse: "sort=-name,created",
+		},
+		{
+			name:    "multiple fields reversed by order",
+			query:   "sort=name,-created&order=desc",
+			order:   types.DESC,
+			reverse: "sort=name,-created",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "https://cattle.io/v1/hobbits?"+tt.query, nil)
+			builder, err := New(req, types.APIVersion{Path: "/v1"}, types.NewSchemas())
+			assert.NoError(t, err)
+
+			reverse, err := url.Parse(builder.ReverseSort(tt.order))
+			assert.NoError(t, err)
+			expected, err := url.ParseQuery(tt.reverse)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, reverse.Query())
+		})
+	}
+
+	req := httptest.NewRequest("GET", "https://cattle.io/v1/hobbits?sort=name&order=desc&marker=x", nil)
+	builder, err := New(req, types.APIVersion{Path: "/v1"}, types.NewSchemas())
+	assert.NoError(t, err)
+	assert.Equal(t, "https://cattle.io/v1/hobbits?sort=state", builder.Sort("state"))
+	assert.Equal(t, "https://cattle.io/v1/hobbits?sort=state%2C-name", builder.(types.MultiSortURLBuilder).SortFields("state", "-name"))
+}